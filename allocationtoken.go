@@ -0,0 +1,46 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+
+	"github.com/nbio/xx"
+)
+
+// Extension data keys for the allocation token extension.
+// https://tools.ietf.org/html/rfc8495
+const (
+	// AllocationTokenKey holds the allocation token sent with a domain
+	// check, create or transfer request.
+	AllocationTokenKey = "allocationToken:allocationToken"
+
+	// AllocationTokenInfoKey, if set to a non-empty value, asks the server
+	// to return the allocation token of a domain in its info response.
+	AllocationTokenInfoKey = "allocationToken:info"
+)
+
+// encodeAllocationToken writes an <allocationToken:allocationToken> element.
+// https://tools.ietf.org/html/rfc8495#section-2.1
+func encodeAllocationToken(buf *bytes.Buffer, token string) {
+	buf.WriteString(`<allocationToken:allocationToken xmlns:allocationToken="`)
+	buf.WriteString(ExtAllocationToken)
+	buf.WriteString(`">`)
+	xml.EscapeText(buf, []byte(token))
+	buf.WriteString(`</allocationToken:allocationToken>`)
+}
+
+// encodeAllocationTokenInfo writes an <allocationToken:info> element.
+// https://tools.ietf.org/html/rfc8495#section-4.1.2
+func encodeAllocationTokenInfo(buf *bytes.Buffer) {
+	buf.WriteString(`<allocationToken:info xmlns:allocationToken="`)
+	buf.WriteString(ExtAllocationToken)
+	buf.WriteString(`"/>`)
+}
+
+func init() {
+	path := "epp > response > extension > " + ExtAllocationToken + " allocationToken"
+	scanResponse.MustHandleCharData(path, func(c *xx.Context) error {
+		c.Value.(*Response).DomainInfoResponse.AllocationToken = string(c.CharData)
+		return nil
+	})
+}
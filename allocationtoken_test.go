@@ -0,0 +1,58 @@
+package epp
+
+import (
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeAllocationToken(t *testing.T) {
+	extData := map[string]string{AllocationTokenKey: "abc&123"}
+
+	x, err := encodeDomainCheck(&Greeting{}, []string{"example.com"}, extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:check></check><extension><allocationToken:allocationToken xmlns:allocationToken="urn:ietf:params:xml:ns:allocationToken-1.0">abc&amp;123</allocationToken:allocationToken></extension></command></epp>`)
+
+	dc := &DomainCreate{Domain: "example.com", AllocationToken: "abc123"}
+	x, err = dc.encode(&Greeting{})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create><extension><allocationToken:allocationToken xmlns:allocationToken="urn:ietf:params:xml:ns:allocationToken-1.0">abc123</allocationToken:allocationToken></extension></command></epp>`)
+
+	x, err = encodeDomainTransfer(&Greeting{}, "request", "example.com", 0, "y", "", extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:transfer></transfer><extension><allocationToken:allocationToken xmlns:allocationToken="urn:ietf:params:xml:ns:allocationToken-1.0">abc&amp;123</allocationToken:allocationToken></extension></command></epp>`)
+
+	x, err = encodeDomainInfo(&Greeting{}, "example.com", "", "", map[string]string{AllocationTokenInfoKey: "1"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:info></info><extension><allocationToken:info xmlns:allocationToken="urn:ietf:params:xml:ns:allocationToken-1.0"/></extension></command></epp>`)
+}
+
+func TestScanAllocationTokenInfo(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:roid>EXAMPLE1-REP</domain:roid>
+      </domain:infData>
+    </resData>
+    <extension>
+      <allocationToken:allocationToken xmlns:allocationToken="urn:ietf:params:xml:ns:allocationToken-1.0">abc123</allocationToken:allocationToken>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainInfoResponse.Domain, "example.com")
+	st.Expect(t, res.DomainInfoResponse.AllocationToken, "abc123")
+}
@@ -2,6 +2,7 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"strings"
@@ -14,21 +15,30 @@ func (c *Conn) CheckDomain(domains ...string) (*DomainCheckResponse, error) {
 	return c.CheckDomainExtensions(domains, nil)
 }
 
+// CheckDomainContext is like CheckDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckDomainContext(ctx context.Context, domains ...string) (*DomainCheckResponse, error) {
+	return c.CheckDomainExtensionsContext(ctx, domains, nil)
+}
+
 // CheckDomainExtensions allows specifying extension data for the following:
 //   - "neulevel:unspec": a string of the Key=Value data for the unspec tag
 //   - "launch:phase": a string of the launch phase
+//   - "allocationToken:allocationToken": an allocation token (RFC 8495)
+//
+// If the server supports the ARI price extension, a second check command
+// is sent for prices, which counts against any RateLimiter.
 func (c *Conn) CheckDomainExtensions(domains []string, extData map[string]string) (*DomainCheckResponse, error) {
-	x, err := encodeDomainCheck(&c.Greeting, domains, extData)
-	if err != nil {
-		return nil, err
-	}
+	return c.CheckDomainExtensionsContext(context.Background(), domains, extData)
+}
 
-	err = c.writeRequest(x)
+// CheckDomainExtensionsContext is like CheckDomainExtensions, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckDomainExtensionsContext(ctx context.Context, domains []string, extData map[string]string) (*DomainCheckResponse, error) {
+	x, err := encodeDomainCheck(&c.Greeting, domains, extData)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -40,11 +50,7 @@ func (c *Conn) CheckDomainExtensions(domains []string, extData map[string]string
 		if err != nil {
 			return nil, err
 		}
-		err = c.writeRequest(x)
-		if err != nil {
-			return nil, err
-		}
-		res2, err := c.readResponse()
+		res2, err := c.request(ctx, x)
 		if err != nil {
 			return nil, err
 		}
@@ -66,37 +72,15 @@ func encodeDomainCheck(greeting *Greeting, domains []string, extData map[string]
 	buf.WriteString(`</domain:check>`)
 	buf.WriteString(`</check>`)
 
-	var feeURN string
-	switch {
-	case greeting.SupportsExtension(ExtFee10):
-		feeURN = ExtFee10
-	case greeting.SupportsExtension(ExtFee21):
-		feeURN = ExtFee21
-	case greeting.SupportsExtension(ExtFee11):
-		feeURN = ExtFee11
-	// Versions 0.8-0.9 require the returned class to be "standard" for
-	// non-premium domains
-	case greeting.SupportsExtension(ExtFee08):
-		feeURN = ExtFee08
-	case greeting.SupportsExtension(ExtFee09):
-		feeURN = ExtFee09
-	// Version 0.5 has an attribute premium="1" for premium domains
-	case greeting.SupportsExtension(ExtFee05):
-		feeURN = ExtFee05
-	// Version 0.6 and 0.7 don't have a standard way of detecting premiums,
-	// so instead there must be matching done on class names
-	case greeting.SupportsExtension(ExtFee06):
-		feeURN = ExtFee06
-	case greeting.SupportsExtension(ExtFee07):
-		feeURN = ExtFee07
-	}
+	feeURN := greeting.feeURN()
 
 	supportsLaunch := extData["launch:phase"] != "" && greeting.SupportsExtension(ExtLaunch)
 	supportsFeePhase := extData["fee:phase"] != ""
 	supportsNeulevel := extData["neulevel:unspec"] != "" && (greeting.SupportsExtension(ExtNeulevel) || greeting.SupportsExtension(ExtNeulevel10))
 	supportsNamestore := extData["namestoreExt:subProduct"] != "" && greeting.SupportsExtension(ExtNamestore)
+	token := extData[AllocationTokenKey]
 
-	hasExtension := feeURN != "" || supportsLaunch || supportsNeulevel || supportsNamestore
+	hasExtension := feeURN != "" || supportsLaunch || supportsNeulevel || supportsNamestore || token != ""
 
 	if hasExtension {
 		buf.WriteString(`<extension>`)
@@ -191,6 +175,10 @@ func encodeDomainCheck(greeting *Greeting, domains []string, extData map[string]
 		buf.WriteString(`</fee:check>`)
 	}
 
+	if token != "" {
+		encodeAllocationToken(buf, token)
+	}
+
 	if hasExtension {
 		buf.WriteString(`</extension>`)
 	}
@@ -226,6 +214,10 @@ type DomainCheckResponse struct {
 	Currency string // Overall currency for the response
 	Checks   []DomainCheck
 	Charges  []DomainCharge
+
+	LaunchPhase string        // <launch:chkData><launch:phase>
+	Claims      []LaunchClaim // <launch:chkData><launch:cd>
+	TransactionID
 }
 
 // DomainCheck represents an EPP <chkData> and associated extension data.
@@ -605,3 +597,70 @@ func init() {
 		return nil
 	})
 }
+
+// CheckHost queries the EPP server for the availability status of one or more hosts.
+// https://tools.ietf.org/html/rfc5732#section-3.1.1
+func (c *Conn) CheckHost(hosts ...string) (*HostCheckResponse, error) {
+	return c.CheckHostContext(context.Background(), hosts...)
+}
+
+// CheckHostContext is like CheckHost, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckHostContext(ctx context.Context, hosts ...string) (*HostCheckResponse, error) {
+	x, err := encodeHostCheck(hosts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.HostCheckResponse, nil
+}
+
+func encodeHostCheck(hosts []string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<check><host:check xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
+	for _, host := range hosts {
+		buf.WriteString(`<host:name>`)
+		xml.EscapeText(buf, []byte(host))
+		buf.WriteString(`</host:name>`)
+	}
+	buf.WriteString(`</host:check></check>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// HostCheckResponse represents an EPP <response> for a host check.
+type HostCheckResponse struct {
+	Checks []HostCheck
+	TransactionID
+}
+
+// HostCheck represents a <host:cd> element in an EPP <chkData>.
+type HostCheck struct {
+	Host      string
+	Reason    string
+	Available bool
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjHost + " chkData"
+	scanResponse.MustHandleStartElement(path+">cd", func(c *xx.Context) error {
+		hcr := &c.Value.(*Response).HostCheckResponse
+		hcr.Checks = append(hcr.Checks, HostCheck{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>name", func(c *xx.Context) error {
+		checks := c.Value.(*Response).HostCheckResponse.Checks
+		check := &checks[len(checks)-1]
+		check.Host = string(c.CharData)
+		check.Available = c.AttrBool("", "avail")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>reason", func(c *xx.Context) error {
+		checks := c.Value.(*Response).HostCheckResponse.Checks
+		check := &checks[len(checks)-1]
+		check.Reason = string(c.CharData)
+		return nil
+	})
+}
@@ -959,3 +959,49 @@ func BenchmarkScanDomainCheckResponse(b *testing.B) {
 		scanResponse.Scan(d, &res)
 	}
 }
+
+func TestEncodeHostCheck(t *testing.T) {
+	x, err := encodeHostCheck([]string{"ns1.example.com", "ns2.example.com"})
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><host:check xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name><host:name>ns2.example.com</host:name></host:check></check></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestScanHostCheckResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <host:chkData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+        <host:cd>
+          <host:name avail="1">ns1.example.com</host:name>
+        </host:cd>
+        <host:cd>
+          <host:name avail="0">ns2.example2.com</host:name>
+          <host:reason>In use</host:reason>
+        </host:cd>
+      </host:chkData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.HostCheckResponse.Checks, []HostCheck{
+		{Host: "ns1.example.com", Available: true},
+		{Host: "ns2.example2.com", Reason: "In use"},
+	})
+}
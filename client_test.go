@@ -8,6 +8,11 @@ import (
 	"github.com/nbio/st"
 )
 
+// testTransactionID returns the clTRID echoed by the canned responses.
+func testTransactionID() string {
+	return "ABC-12345"
+}
+
 func TestClientDomainInfo(t *testing.T) {
 	ls, err := newLocalServer()
 	st.Assert(t, err, nil)
@@ -71,6 +76,7 @@ func TestClientDomainInfo(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 
 	err = c.Hello()
 	st.Assert(t, err, nil)
@@ -133,6 +139,7 @@ func TestClientDomainRenew(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	err = c.Hello()
 	st.Assert(t, err, nil)
 
@@ -195,6 +202,7 @@ func TestClientCreateDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	err = c.Hello()
 	st.Assert(t, err, nil)
 
@@ -260,6 +268,7 @@ func TestClientTransferDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	err = c.Hello()
 	st.Assert(t, err, nil)
 
@@ -309,10 +318,13 @@ func TestClientDeleteDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
-	err = c.DeleteDomain("example.com", nil)
+	trID, err := c.DeleteDomain("example.com", nil)
 	st.Expect(t, err, nil)
+	st.Expect(t, trID.ClTRID, "ABC-12345")
+	st.Expect(t, trID.SvTRID, "54321-XYZ")
 }
 
 func TestClientRestoreDomain(t *testing.T) {
@@ -354,6 +366,7 @@ func TestClientRestoreDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	_, err = c.RestoreDomain("example.com", nil)
@@ -398,9 +411,10 @@ func TestClientUpdateDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
-	err = c.UpdateDomain("example.com", nil, nil, nil)
+	err = c.UpdateDomain("example.com", nil, nil, map[string]string{"registrant": "reg2"})
 	st.Expect(t, err, nil)
 }
 
@@ -449,6 +463,7 @@ func TestClientCheckDomain(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	res, err := c.CheckDomain("example.com")
@@ -501,6 +516,7 @@ func TestClientCreateContact(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	pi := PostalInfo{Name: "John Doe", City: "Miami", CC: "US"}
@@ -554,6 +570,7 @@ func TestClientCreateHost(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	res, err := c.CreateHost("ns1.example.com", []string{"192.0.2.2"}, nil)
@@ -610,6 +627,7 @@ func TestClientContactInfo(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	res, err := c.ContactInfo("sh8013", "2fooBAR", nil)
@@ -662,6 +680,7 @@ func TestClientPollReq(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
 	res, err := c.PollReq()
@@ -709,8 +728,10 @@ func TestClientDeleteContact(t *testing.T) {
 	st.Assert(t, err, nil)
 	c, err := NewConn(nc)
 	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
 	c.Hello()
 
-	err = c.DeleteContact("sh8013", nil)
+	trID, err := c.DeleteContact("sh8013", nil)
 	st.Expect(t, err, nil)
+	st.Expect(t, trID.ClTRID, "ABC-12345")
 }
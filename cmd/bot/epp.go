@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net"
+	"time"
 
 	epp "github.com/onasunnymorning/eppclient"
 )
@@ -19,13 +22,11 @@ type Config struct {
 	Cert     string
 	Key      string
 	CACert   string
+	Sessions int
 }
 
-// connectEPP establishes a connection to the EPP server using the provided configuration.
-func connectEPP(cfg *Config) (*epp.Conn, error) {
-	// TODO: Connection pooling could be implemented here or managed globally to avoid
-	// establishing a new connection per request. For now, we connect on demand.
-	
+// dialEPP opens a network connection to the EPP server using the provided configuration.
+func dialEPP(ctx context.Context, cfg *Config) (net.Conn, error) {
 	tlsCfg := &tls.Config{
 		InsecureSkipVerify: true,
 	}
@@ -57,14 +58,15 @@ func connectEPP(cfg *Config) (*epp.Conn, error) {
 		tlsCfg = nil
 	}
 
-	conn, err := net.Dial("tcp", cfg.Addr)
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", cfg.Addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial: %w", err)
 	}
 
 	if tlsCfg != nil {
 		tc := tls.Client(conn, tlsCfg)
-		err = tc.Handshake()
+		err = tc.HandshakeContext(ctx)
 		if err != nil {
 			conn.Close()
 			return nil, fmt.Errorf("tls handshake failed: %w", err)
@@ -72,45 +74,40 @@ func connectEPP(cfg *Config) (*epp.Conn, error) {
 		conn = tc
 	}
 
-	// Disable debug logging for bot to avoid cluttering stdout unless needed
-	logger := epp.DebugLogger
-	epp.DebugLogger = nil
-	
-	c, err := epp.NewConn(conn)
-	epp.DebugLogger = logger
-	
-	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("epp handshake failed: %w", err)
-	}
-
-	logger = epp.DebugLogger
-	epp.DebugLogger = nil
-	_, err = c.Login(cfg.User, cfg.Password, "")
-	epp.DebugLogger = logger
-	
-	if err != nil {
-		c.Close()
-		return nil, fmt.Errorf("epp login failed: %w", err)
-	}
+	return conn, nil
+}
 
-	return c, nil
+// newPool returns a pool of logged-in EPP sessions shared by all Slack commands.
+func newPool(cfg *Config) *epp.Pool {
+	return epp.NewPool(epp.PoolConfig{
+		Dial: func(ctx context.Context) (net.Conn, error) {
+			return dialEPP(ctx, cfg)
+		},
+		User:        cfg.User,
+		Password:    cfg.Password,
+		Timeout:     30 * time.Second,
+		MaxSessions: cfg.Sessions,
+		KeepAlive:   5 * time.Minute,
+		Logger:      slog.Default(),
+	})
 }
 
 // checkDomain runs the EPP check command against a given domain list.
-func checkDomain(cfg *Config, domains []string) (*epp.DomainCheckResponse, error) {
-	c, err := connectEPP(cfg)
-	if err != nil {
-		return nil, err
-	}
-	defer c.Close()
+func checkDomain(pool *epp.Pool, domains []string) (*epp.DomainCheckResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 
 	// By requesting a fee check for 1 period, the server will return currency and pricing info.
 	extData := map[string]string{
 		"fee:period": "1",
 	}
 
-	dc, err := c.CheckDomainExtensions(domains, extData)
+	var dc *epp.DomainCheckResponse
+	err := pool.DoRetry(ctx, func(c *epp.Conn) error {
+		var err error
+		dc, err = c.CheckDomainExtensionsContext(ctx, domains, extData)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("check domain failed: %w", err)
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/joho/godotenv"
@@ -35,11 +36,15 @@ func main() {
 		Key:      os.Getenv("KEY"),
 		CACert:   os.Getenv("CACERT"),
 	}
+	cfg.Sessions, _ = strconv.Atoi(os.Getenv("SESSIONS"))
 
 	if cfg.Addr == "" || cfg.User == "" {
 		log.Fatalf("EPP config missing ADDR or USER in .env")
 	}
 
+	pool := newPool(cfg)
+	defer pool.Close()
+
 	api := slack.New(
 		botToken,
 		slack.OptionAppLevelToken(appToken),
@@ -66,7 +71,7 @@ func main() {
 				client.Ack(*evt.Request)
 
 				if cmd.Command == "/epp" {
-					go handleEppCommand(api, cmd, cfg)
+					go handleEppCommand(api, cmd, cfg, pool)
 				} else {
 					log.Printf("Unknown command received: %s", cmd.Command)
 				}
@@ -84,7 +89,7 @@ func main() {
 	}
 }
 
-func handleEppCommand(api *slack.Client, cmd slack.SlashCommand, cfg *Config) {
+func handleEppCommand(api *slack.Client, cmd slack.SlashCommand, cfg *Config, pool *epp.Pool) {
 	args := strings.Fields(cmd.Text)
 	if len(args) < 2 || args[0] != "check" {
 		replyError(api, cmd.ChannelID, cmd.UserID, "Usage: `/epp check <domain>`")
@@ -100,7 +105,7 @@ func handleEppCommand(api *slack.Client, cmd slack.SlashCommand, cfg *Config) {
 	)
 
 	// Call EPP
-	dcr, err := checkDomain(cfg, domains)
+	dcr, err := checkDomain(pool, domains)
 	if err != nil {
 		replyError(api, cmd.ChannelID, cmd.UserID, fmt.Sprintf("Error running check: %v", err))
 		return
@@ -2,15 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +25,8 @@ import (
 var (
 	profileName string
 	verbose     bool
+	redact      string
+	noRedact    bool
 	version     = "dev"
 	commit      = "none"
 )
@@ -29,6 +35,8 @@ func main() {
 	// Global flags
 	flag.StringVar(&profileName, "profile", "default", "profile name in ~/.epp/credentials")
 	flag.BoolVar(&verbose, "v", false, "enable verbose debug logging")
+	flag.StringVar(&redact, "redact", "", "comma-separated extra `paths` to redact in logged XML, e.g. contact:email,postalInfo/name")
+	flag.BoolVar(&noRedact, "no-redact", false, "log credentials and authInfo without redaction")
 
 	// Capture logs
 	var logBuf bytes.Buffer
@@ -42,7 +50,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  poll    Check EPP poll messages\n")
 		fmt.Fprintf(os.Stderr, "  transfer Transfer a domain\n")
 		fmt.Fprintf(os.Stderr, "  raw     Send raw XML from a file or stdin\n")
-		fmt.Fprintf(os.Stderr, "  info    Get domain info\n")
+		fmt.Fprintf(os.Stderr, "  info    Get domain, contact or host info\n")
 		fmt.Fprintf(os.Stderr, "  update  Update domain, contact or host\n")
 		fmt.Fprintf(os.Stderr, "  version Print version information\n")
 		fmt.Fprintf(os.Stderr, "\nOptions:\n")
@@ -66,10 +74,14 @@ func main() {
 	// A better way is to parse, then look at remaining args.
 	flag.Parse()
 
+	epp.Redact = !noRedact
+	if redact != "" {
+		epp.RedactPaths = strings.Split(redact, ",")
+	}
+	h := &logHandler{x: &logBuf, events: io.Discard}
 	if verbose {
-		epp.DebugLogger = io.MultiWriter(os.Stderr, &logBuf)
-	} else {
-		epp.DebugLogger = &logBuf
+		h.x = io.MultiWriter(os.Stderr, &logBuf)
+		h.events = os.Stderr
 	}
 
 	args := flag.Args()
@@ -96,10 +108,10 @@ func main() {
 	}
 
 	conn := connect(cfg)
+	conn.Logger = slog.New(h)
 
 	defer func() {
-		logger := epp.DebugLogger
-		epp.DebugLogger = nil
+		conn.Logger = nil
 		if r := recover(); r != nil {
 			// If it was our fatal error, we already logged it.
 			// Just ensure we prompt and then exit 1.
@@ -107,12 +119,10 @@ func main() {
 			// but for this CLI it's probably fine to treat all panics as "something went wrong".
 			// But we DO want to run promptRawXML.
 			conn.Close()
-			epp.DebugLogger = logger
 			promptRawXML(&logBuf)
 			os.Exit(1)
 		}
 		conn.Close()
-		epp.DebugLogger = logger
 		promptRawXML(&logBuf)
 	}()
 
@@ -153,12 +163,12 @@ func checkUsage(cmd string, args []string) {
 		}
 	case "info":
 		if len(args) == 0 {
-			fmt.Fprintln(os.Stderr, "Usage: epp info <domain|contact> [options]")
+			fmt.Fprintln(os.Stderr, "Usage: epp info <domain|contact|host> [options]")
 			os.Exit(1)
 		}
 		sub := args[0]
-		if sub != "domain" && sub != "contact" {
-			fmt.Fprintf(os.Stderr, "Unknown info type: %s. Use 'domain' or 'contact'.\n", sub)
+		if sub != "domain" && sub != "contact" && sub != "host" {
+			fmt.Fprintf(os.Stderr, "Unknown info type: %s. Use 'domain', 'contact' or 'host'.\n", sub)
 			os.Exit(1)
 		}
 	case "delete":
@@ -206,12 +216,12 @@ func checkUsage(cmd string, args []string) {
 		// No strict enforcement needed for req
 	case "transfer":
 		if len(args) == 0 {
-			fmt.Fprintln(os.Stderr, "Usage: epp transfer <domain> [options]")
+			fmt.Fprintln(os.Stderr, "Usage: epp transfer <domain|contact> [options]")
 			os.Exit(1)
 		}
 		sub := args[0]
-		if sub != "domain" {
-			fmt.Fprintf(os.Stderr, "Unknown transfer type: %s. Use 'domain'.\n", sub)
+		if sub != "domain" && sub != "contact" {
+			fmt.Fprintf(os.Stderr, "Unknown transfer type: %s. Use 'domain' or 'contact'.\n", sub)
 			os.Exit(1)
 		}
 	case "raw":
@@ -283,17 +293,21 @@ func connect(cfg *Config) *epp.Conn {
 	}
 
 	color.Fprintf(os.Stderr, "Performing EPP handshake\n")
-	logger := epp.DebugLogger
-	epp.DebugLogger = nil
 	c, err := epp.NewConn(conn)
-	epp.DebugLogger = logger
 	fatalif(err)
 
 	color.Fprintf(os.Stderr, "Logging in as %s...\n", cfg.User)
-	logger = epp.DebugLogger
-	epp.DebugLogger = nil
-	_, err = c.Login(cfg.User, cfg.Password, "")
-	epp.DebugLogger = logger
+	c.UserAgent = &epp.UserAgent{
+		App:  "epp/" + version,
+		Tech: runtime.Version(),
+		OS:   runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	res, err := c.Login(cfg.User, cfg.Password, "")
+	var r *epp.Result
+	if errors.As(err, &r) {
+		res = *r
+	}
+	printSecurityEvents(res.SecurityEvents)
 	fatalif(err)
 
 	return c
@@ -303,10 +317,12 @@ func runCheck(c *epp.Conn, args []string) {
 	fs := flag.NewFlagSet("check", flag.ExitOnError)
 	phase := fs.String("phase", "", "launch phase (e.g., sunrise, open)")
 	period := fs.Int("period", 1, "registration period in years")
+	claims := fs.Bool("claims", false, "perform a trademark claims check")
+	token := fs.String("token", "", "allocation token (RFC 8495)")
 	fs.Parse(args)
 
 	if fs.NArg() == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: epp check [-phase phase] [-period N] <domain>...")
+		fmt.Fprintln(os.Stderr, "Usage: epp check [-phase phase] [-period N] [-claims] [-token token] <domain>...")
 		os.Exit(1)
 	}
 
@@ -314,11 +330,22 @@ func runCheck(c *epp.Conn, args []string) {
 	var dc *epp.DomainCheckResponse
 	var err error
 
+	if *claims {
+		dc, err = c.CheckDomainLaunch(fs.Args(), &epp.LaunchCheck{Type: epp.LaunchCheckClaims, Phase: *phase})
+		fatalif(err)
+		printClaims(dc)
+		color.Fprintf(os.Stderr, "@{.}Query: %s\n", time.Since(start))
+		return
+	}
+
 	extData := make(map[string]string)
 	if *phase != "" {
 		extData["launch:phase"] = *phase
 		extData["fee:phase"] = *phase
 	}
+	if *token != "" {
+		extData[epp.AllocationTokenKey] = *token
+	}
 
 	extData["fee:period"] = fmt.Sprintf("%d", *period)
 
@@ -343,25 +370,77 @@ func runInfo(c *epp.Conn, args []string) {
 		runInfoDomain(c, subArgs)
 	case "contact":
 		runInfoContact(c, subArgs)
+	case "host":
+		runInfoHost(c, subArgs)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown info type: %s. Use 'domain' or 'contact'.\n", cmd)
+		fmt.Fprintf(os.Stderr, "Unknown info type: %s. Use 'domain', 'contact' or 'host'.\n", cmd)
 		os.Exit(1)
 	}
 }
 
 func runInfoDomain(c *epp.Conn, args []string) {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: epp info domain <domain>")
+	fs := flag.NewFlagSet("info domain", flag.ExitOnError)
+	hosts := fs.String("hosts", epp.HostsNone, "host information to return (all, del, sub or none)")
+	auth := fs.String("auth", "", "auth info (for domains sponsored by another registrar)")
+	phase := fs.String("phase", "", "launch phase, to query a launch application")
+	appID := fs.String("application-id", "", "launch application ID")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: epp info domain [-hosts all|del|sub|none] [-auth code] [-phase phase -application-id id] <domain>")
 		os.Exit(1)
 	}
-	res, err := c.DomainInfo(args[0], nil)
+	var res *epp.DomainInfoResponse
+	var err error
+	if *phase != "" {
+		res, err = c.DomainInfoLaunch(fs.Arg(0), &epp.LaunchApplication{Phase: *phase, ApplicationID: *appID})
+	} else {
+		res, err = c.DomainInfoAuth(fs.Arg(0), *hosts, *auth, nil)
+	}
 	fatalif(err)
 
 	fmt.Printf("Domain: %s\n", res.Domain)
 	fmt.Printf("ROID: %s\n", res.ID)
 	fmt.Printf("Status: %v\n", res.Status)
+	if len(res.RGPStatus) > 0 {
+		fmt.Printf("RGP Status: %v\n", res.RGPStatus)
+	}
+	if res.Launch.Phase != "" {
+		fmt.Printf("Launch Phase: %s\n", res.Launch.Phase)
+		fmt.Printf("Application ID: %s\n", res.Launch.ApplicationID)
+		fmt.Printf("Application Status: %s\n", res.Launch.Status)
+	}
+	if res.Registrant != "" {
+		fmt.Printf("Registrant: %s\n", res.Registrant)
+	}
+	for _, contact := range res.Contacts {
+		fmt.Printf("Contact (%s): %s\n", contact.Type, contact.ID)
+	}
+	for _, ns := range res.Nameservers {
+		fmt.Printf("Nameserver: %s\n", ns)
+	}
+	for _, ns := range res.NameserverAttrs {
+		fmt.Printf("Nameserver: %s", ns.Name)
+		for _, addr := range ns.Addrs {
+			fmt.Printf(" %s", addr.Address)
+		}
+		fmt.Println()
+	}
+	for _, host := range res.Hosts {
+		fmt.Printf("Host: %s\n", host)
+	}
+	for _, ds := range res.SecDNS.DS {
+		fmt.Printf("DS: %d %d %d %s\n", ds.KeyTag, ds.Alg, ds.DigestType, ds.Digest)
+	}
+	for _, key := range res.SecDNS.Keys {
+		fmt.Printf("DNSKEY: %d %d %d %s\n", key.Flags, key.Protocol, key.Alg, key.PubKey)
+	}
+	fmt.Printf("Sponsor: %s\n", res.ClID)
 	fmt.Printf("Created: %s\n", res.CrDate)
 	fmt.Printf("Expires: %s\n", res.ExDate)
+	if res.AuthInfo != "" {
+		fmt.Printf("Auth Info: %s\n", res.AuthInfo)
+	}
 }
 
 func runInfoContact(c *epp.Conn, args []string) {
@@ -380,7 +459,47 @@ func runInfoContact(c *epp.Conn, args []string) {
 	fmt.Printf("Contact: %s\n", res.ID)
 	fmt.Printf("ROID: %s\n", res.ROID)
 	fmt.Printf("Status: %v\n", res.Status)
+	for _, pi := range res.Postal {
+		fmt.Printf("Name (%s): %s\n", pi.Type, pi.Name)
+		if pi.Org != "" {
+			fmt.Printf("Org (%s): %s\n", pi.Type, pi.Org)
+		}
+		for _, street := range []string{pi.Street, pi.Street2, pi.Street3} {
+			if street != "" {
+				fmt.Printf("Street (%s): %s\n", pi.Type, street)
+			}
+		}
+		fmt.Printf("City (%s): %s %s %s %s\n", pi.Type, pi.City, pi.SP, pi.PC, pi.CC)
+	}
+	if res.Voice != "" {
+		fmt.Printf("Voice: %s\n", res.Voice)
+	}
+	if res.Fax != "" {
+		fmt.Printf("Fax: %s\n", res.Fax)
+	}
 	fmt.Printf("Email: %s\n", res.Email)
+	fmt.Printf("Sponsor: %s\n", res.ClID)
+	fmt.Printf("Created: %s\n", res.CrDate)
+}
+
+func runInfoHost(c *epp.Conn, args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: epp info host <host>")
+		os.Exit(1)
+	}
+	res, err := c.HostInfo(args[0])
+	fatalif(err)
+
+	fmt.Printf("Host: %s\n", res.Host)
+	fmt.Printf("ROID: %s\n", res.ID)
+	fmt.Printf("Status: %v\n", res.Status)
+	for _, ip := range res.IPv4 {
+		fmt.Printf("IPv4: %s\n", ip)
+	}
+	for _, ip := range res.IPv6 {
+		fmt.Printf("IPv6: %s\n", ip)
+	}
+	fmt.Printf("Sponsor: %s\n", res.ClID)
 	fmt.Printf("Created: %s\n", res.CrDate)
 }
 
@@ -402,13 +521,24 @@ func runDelete(c *epp.Conn, args []string) {
 }
 
 func runDeleteDomain(c *epp.Conn, args []string) {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: epp delete domain <domain>")
+	fs := flag.NewFlagSet("delete domain", flag.ExitOnError)
+	phase := fs.String("phase", "", "launch phase, to delete a launch application")
+	appID := fs.String("application-id", "", "launch application ID")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: epp delete domain [-phase phase -application-id id] <domain>")
 		os.Exit(1)
 	}
-	err := c.DeleteDomain(args[0], nil)
+	domain := fs.Arg(0)
+	var err error
+	if *phase != "" || *appID != "" {
+		_, err = c.DeleteDomainLaunch(domain, &epp.LaunchApplication{Phase: *phase, ApplicationID: *appID})
+	} else {
+		_, err = c.DeleteDomain(domain, nil)
+	}
 	fatalif(err)
-	color.Printf("@{g}Domain %s deleted!\n", args[0])
+	color.Printf("@{g}Domain %s deleted!\n", domain)
 }
 
 func runDeleteContact(c *epp.Conn, args []string) {
@@ -416,7 +546,7 @@ func runDeleteContact(c *epp.Conn, args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: epp delete contact <contact-id>")
 		os.Exit(1)
 	}
-	err := c.DeleteContact(args[0], nil)
+	_, err := c.DeleteContact(args[0], nil)
 	fatalif(err)
 	color.Printf("@{g}Contact %s deleted!\n", args[0])
 }
@@ -426,7 +556,7 @@ func runDeleteHost(c *epp.Conn, args []string) {
 		fmt.Fprintln(os.Stderr, "Usage: epp delete host <host>")
 		os.Exit(1)
 	}
-	err := c.DeleteHost(args[0])
+	_, err := c.DeleteHost(args[0])
 	fatalif(err)
 	color.Printf("@{g}Host %s deleted!\n", args[0])
 }
@@ -468,6 +598,15 @@ func runCreateDomain(c *epp.Conn, args []string) {
 	fee := fs.String("fee", "", "fee amount (requires -currency usually)")
 	currency := fs.String("currency", "", "fee currency")
 	phase := fs.String("phase", "", "launch phase (e.g., sunrise, open)")
+	launchType := fs.String("launch-type", "", "launch object type (application or registration)")
+	smdFile := fs.String("smd", "", "signed mark data (SMD) file, or a file with only its base64 body")
+	noticeID := fs.String("notice-id", "", "accepted claims notice ID")
+	noticeValidator := fs.String("notice-validator", "", "claims notice validator ID")
+	notAfter := fs.String("not-after", "", "claims notice expiry (RFC 3339)")
+	acceptedDate := fs.String("accepted-date", "", "claims notice acceptance time (RFC 3339), defaults to now")
+	dsParams := fs.String("ds", "", "comma separated DS records (keyTag:alg:digestType:digest)")
+	maxSigLife := fs.Int("max-sig-life", 0, "DNSSEC maximum signature lifetime in seconds")
+	token := fs.String("token", "", "allocation token (RFC 8495)")
 
 	fs.Parse(args)
 
@@ -478,42 +617,59 @@ func runCreateDomain(c *epp.Conn, args []string) {
 
 	domain := fs.Arg(0)
 
-	contacts := make(map[string]string)
-	if *admin != "" {
-		contacts["admin"] = *admin
+	dc := &epp.DomainCreate{
+		Domain:     domain,
+		Period:     *period,
+		Unit:       "y",
+		Registrant: *registrant,
+		Contacts:   parseContacts(*admin, *tech, *billing),
+		AuthInfo:   *auth,
+
+		AllocationToken: *token,
 	}
-	if *tech != "" {
-		contacts["tech"] = *tech
+	if *nsParams != "" {
+		dc.Nameservers = parseList(*nsParams)
 	}
-	if *billing != "" {
-		contacts["billing"] = *billing
+	if *dsParams != "" || *maxSigLife > 0 {
+		dc.SecDNS = &epp.SecDNSData{MaxSigLife: *maxSigLife}
+		if *dsParams != "" {
+			dc.SecDNS.DS = parseDS(*dsParams)
+		}
 	}
 
-	var ns []string
-	if *nsParams != "" {
-		ns = strings.Split(*nsParams, ",")
-		for i, n := range ns {
-			ns[i] = strings.TrimSpace(n)
+	if *fee != "" {
+		dc.Extensions = map[string]string{"fee:fee": *fee}
+		if *currency != "" {
+			dc.Extensions["fee:currency"] = *currency
 		}
 	}
-
-	var extData map[string]string
-	if *fee != "" || *phase != "" {
-		extData = make(map[string]string)
-		if *fee != "" {
-			extData["fee:fee"] = *fee
-			if *currency != "" {
-				extData["fee:currency"] = *currency
-			}
+	if *phase != "" {
+		dc.Launch = &epp.LaunchCreate{Phase: *phase, Type: *launchType}
+		if *smdFile != "" {
+			smd, err := ioutil.ReadFile(*smdFile)
+			fatalif(err)
+			dc.Launch.SMD = parseSMD(string(smd))
 		}
-		if *phase != "" {
-			extData["launch:phase"] = *phase
+		if *noticeID != "" {
+			notice := epp.LaunchNotice{NoticeID: *noticeID, ValidatorID: *noticeValidator, AcceptedDate: time.Now()}
+			var err error
+			notice.NotAfter, err = time.Parse(time.RFC3339, *notAfter)
+			fatalif(err)
+			if *acceptedDate != "" {
+				notice.AcceptedDate, err = time.Parse(time.RFC3339, *acceptedDate)
+				fatalif(err)
+			}
+			dc.Launch.Notices = []epp.LaunchNotice{notice}
 		}
 	}
 
-	res, err := c.CreateDomain(domain, *period, "y", *auth, *registrant, contacts, ns, extData)
+	res, err := c.DomainCreate(dc)
 	fatalif(err)
 	color.Printf("@{g}Domain %s created!\nCreated: %s\nExpiry: %s\n", res.Domain, res.CrDate, res.ExDate)
+	if res.ApplicationID != "" {
+		fmt.Printf("Application ID: %s\n", res.ApplicationID)
+	}
+	printFeeData(&res.Fee)
 }
 
 func runCreateContact(c *epp.Conn, args []string) {
@@ -646,6 +802,7 @@ func runRenewDomain(c *epp.Conn, args []string) {
 	res, err := c.RenewDomain(domain, date, *period, "y", extData)
 	fatalif(err)
 	color.Printf("@{g}Domain %s renewed!\nNew Expiry: %s\n", res.Domain, res.ExDate)
+	printFeeData(&res.Fee)
 }
 
 func runRestore(c *epp.Conn, args []string) {
@@ -662,16 +819,125 @@ func runRestore(c *epp.Conn, args []string) {
 }
 
 func runRestoreDomain(c *epp.Conn, args []string) {
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: epp restore domain <domain>")
+	fs := flag.NewFlagSet("restore domain", flag.ExitOnError)
+	report := fs.Bool("report", false, "send the restore report (RGP step two) instead of a restore request")
+	preData := fs.String("pre-data", "", "registration data before deletion")
+	postData := fs.String("post-data", "", "registration data after restore")
+	delTime := fs.String("del-time", "", "deletion time (RFC 3339)")
+	resTime := fs.String("res-time", "", "restore request time (RFC 3339), defaults to now")
+	reason := fs.String("reason", "", "reason for the restore")
+	statement1 := fs.String("statement1", rgpStatement1, "first registrar statement")
+	statement2 := fs.String("statement2", rgpStatement2, "second registrar statement")
+	other := fs.String("other", "", "other supporting information")
+	fee := fs.String("fee", "", "restore fee amount")
+	currency := fs.String("currency", "", "fee currency")
+
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: epp restore domain [-report -pre-data data -post-data data -del-time time -reason text] <domain>")
+		fs.PrintDefaults()
 		os.Exit(1)
 	}
-	// Restore often requires RGP extension
-	// TODO: Add support for reporting data if required by registry?
-	// For now, simple RGP restore request.
-	_, err := c.RestoreDomain(args[0], nil)
+
+	domain := fs.Arg(0)
+
+	if !*report {
+		var extData map[string]string
+		if *fee != "" {
+			extData = map[string]string{"fee:fee": *fee}
+			if *currency != "" {
+				extData["fee:currency"] = *currency
+			}
+		}
+		res, err := c.RestoreDomain(domain, extData)
+		fatalif(err)
+		color.Printf("@{g}Domain %s restore requested!\n", domain)
+		printRGPStatus(res.RGPStatus)
+		printFeeData(&res.Fee)
+		return
+	}
+
+	r := &epp.RestoreReport{
+		PreData:    *preData,
+		PostData:   *postData,
+		ResReason:  *reason,
+		Statements: []string{*statement1, *statement2},
+		Other:      *other,
+		ResTime:    time.Now(),
+	}
+	var err error
+	if *delTime != "" {
+		r.DelTime, err = time.Parse(time.RFC3339, *delTime)
+		fatalif(err)
+	}
+	if *resTime != "" {
+		r.ResTime, err = time.Parse(time.RFC3339, *resTime)
+		fatalif(err)
+	}
+
+	res, err := c.RestoreDomainReport(domain, r)
 	fatalif(err)
-	color.Printf("@{g}Domain %s restored!\n", args[0])
+	color.Printf("@{g}Domain %s restore report accepted!\n", domain)
+	printRGPStatus(res.RGPStatus)
+}
+
+// Default restore report statements, from RFC 3915 section 4.2.5.
+const (
+	rgpStatement1 = "This registrar has not restored the Registered Name in order to assume the rights to use or sell the Registered Name for itself or for any third party."
+	rgpStatement2 = "The information in this report is true to best of this registrar's knowledge, and this registrar acknowledges that intentionally supplying false information in this report shall constitute an incurable material breach of the Registry-Registrar Agreement."
+)
+
+// printFeeData prints the fees charged by a transform command, if any.
+func printFeeData(fd *epp.FeeData) {
+	for _, fee := range fd.Fees {
+		fmt.Printf("Fee: %s %s", fee.Amount, fd.Currency)
+		if fee.Description != "" {
+			fmt.Printf(" (%s)", fee.Description)
+		}
+		fmt.Println()
+	}
+	for _, credit := range fd.Credits {
+		fmt.Printf("Credit: %s %s\n", credit.Amount, fd.Currency)
+	}
+	if fd.Balance != "" {
+		fmt.Printf("Balance: %s %s\n", fd.Balance, fd.Currency)
+	}
+	if fd.CreditLimit != "" {
+		fmt.Printf("Credit Limit: %s %s\n", fd.CreditLimit, fd.Currency)
+	}
+}
+
+func printSecurityEvents(events []epp.SecurityEvent) {
+	for _, e := range events {
+		level := "@{y}"
+		if e.IsError() {
+			level = "@{r}"
+		}
+		msg := e.Type
+		if e.Name != "" {
+			msg += " " + e.Name
+		}
+		if !e.ExDate.IsZero() {
+			msg += " expires " + e.ExDate.Format(time.RFC3339)
+		}
+		if e.Value != "" {
+			msg += " value " + e.Value
+		}
+		if e.Duration != "" {
+			msg += " over " + e.Duration
+		}
+		if e.Description != "" {
+			msg += ": " + e.Description
+		}
+		color.Fprintf(os.Stderr, level+"Security %s: %s\n", e.Level, msg)
+	}
+}
+
+func printRGPStatus(status []string) {
+	if len(status) > 0 {
+		fmt.Printf("RGP Status: %v\n", status)
+	}
 }
 
 func runTransfer(c *epp.Conn, args []string) {
@@ -681,8 +947,10 @@ func runTransfer(c *epp.Conn, args []string) {
 	switch cmd {
 	case "domain":
 		runTransferDomain(c, subArgs)
+	case "contact":
+		runTransferContact(c, subArgs)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown transfer type: %s. Use 'domain'.\n", cmd)
+		fmt.Fprintf(os.Stderr, "Unknown transfer type: %s. Use 'domain' or 'contact'.\n", cmd)
 		os.Exit(1)
 	}
 }
@@ -690,8 +958,35 @@ func runTransfer(c *epp.Conn, args []string) {
 func runPoll(c *epp.Conn, args []string) {
 	fs := flag.NewFlagSet("poll", flag.ExitOnError)
 	ack := fs.String("ack", "", "acknowledge message ID")
+	drain := fs.Bool("drain", false, "print and acknowledge every message in the queue")
+	journal := fs.String("journal", "", "journal file of handled messages, used with -drain")
 	fs.Parse(args)
 
+	if *drain {
+		cfg := epp.PollWorkerConfig{
+			Do: func(ctx context.Context, f func(c *epp.Conn) error) error {
+				return f(c)
+			},
+			Other: func(ctx context.Context, pr *epp.PollResponse) error {
+				color.Printf("@{g}Message ID: %s\n", pr.ID)
+				color.Printf("Date: %s\n", pr.Date.Format(time.RFC3339))
+				color.Printf("Message: %s\n", pr.Message)
+				printPollMessage(pr.Data)
+				return nil
+			},
+		}
+		if *journal != "" {
+			j, err := epp.OpenFileJournal(*journal)
+			fatalif(err)
+			defer j.Close()
+			cfg.Journal = j
+		}
+		n, err := epp.NewPollWorker(cfg).Drain(context.Background())
+		color.Printf("@{.}Messages acknowledged: %d\n", n)
+		fatalif(err)
+		return
+	}
+
 	if *ack != "" {
 		res, err := c.PollAck(*ack)
 		fatalif(err)
@@ -714,6 +1009,47 @@ func runPoll(c *epp.Conn, args []string) {
 	color.Printf("Count: %d\n", res.Count)
 	color.Printf("Date: %s\n", res.Date.Format(time.RFC3339))
 	color.Printf("Message: %s\n", res.Message)
+	printPollMessage(res.Data)
+}
+
+func printPollMessage(m epp.PollMessage) {
+	switch m := m.(type) {
+	case *epp.PollTransfer:
+		fmt.Printf("Transfer: %s %s\n", m.ID, m.Status)
+		fmt.Printf("Requested: %s by %s\n", m.REDate.Format(time.RFC3339), m.REID)
+		fmt.Printf("Action due: %s by %s\n", m.ACDate.Format(time.RFC3339), m.ACID)
+		if !m.ExDate.IsZero() {
+			fmt.Printf("Expiry: %s\n", m.ExDate.Format(time.RFC3339))
+		}
+	case *epp.PollPendingAction:
+		result := "failed"
+		if m.Result {
+			result = "succeeded"
+		}
+		fmt.Printf("Pending action on %s %s at %s (clTRID %s, svTRID %s)\n", m.ID, result, m.Date.Format(time.RFC3339), m.ClTRID, m.SvTRID)
+	case *epp.PollLowBalance:
+		fmt.Printf("Low balance: %s available of %s credit limit (threshold %s %s)\n", m.AvailableCredit, m.CreditLimit, m.CreditThreshold, m.ThresholdType)
+	case *epp.PollChange:
+		op := m.Operation
+		if m.OperationName != "" {
+			op += " (" + m.OperationName + ")"
+		}
+		fmt.Printf("Change: %s at %s by %s, state %s\n", op, m.Date.Format(time.RFC3339), m.Who, m.State)
+		if m.CaseID != "" {
+			fmt.Printf("Case: %s %s\n", m.CaseType, m.CaseID)
+		}
+		if m.Reason != "" {
+			fmt.Printf("Reason: %s\n", m.Reason)
+		}
+		switch {
+		case m.Domain != nil:
+			fmt.Printf("Domain: %s\nStatus: %s\n", m.Domain.Domain, strings.Join(m.Domain.Status, ", "))
+		case m.Contact != nil:
+			fmt.Printf("Contact: %s\n", m.Contact.ID)
+		case m.Host != nil:
+			fmt.Printf("Host: %s\n", m.Host.Host)
+		}
+	}
 }
 
 func runTransferDomain(c *epp.Conn, args []string) {
@@ -723,29 +1059,33 @@ func runTransferDomain(c *epp.Conn, args []string) {
 	period := fs.Int("period", 1, "registration period in years (optional for request)")
 	fee := fs.String("fee", "", "fee amount")
 	currency := fs.String("currency", "", "fee currency")
+	token := fs.String("token", "", "allocation token (RFC 8495)")
 	fs.Parse(args)
 
 	if fs.NArg() == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: epp transfer domain [-op op] [-auth code] [-period N] [-fee amount] [-currency code] <domain>")
+		fmt.Fprintln(os.Stderr, "Usage: epp transfer domain [-op op] [-auth code] [-period N] [-fee amount] [-currency code] [-token token] <domain>")
 		os.Exit(1)
 	}
 
 	domain := fs.Arg(0)
 
-	var extData map[string]string
+	extData := make(map[string]string)
 	if *fee != "" {
-		extData = make(map[string]string)
 		extData["fee:fee"] = *fee
 		if *currency != "" {
 			extData["fee:currency"] = *currency
 		}
 	}
+	if *token != "" {
+		extData[epp.AllocationTokenKey] = *token
+	}
 
 	res, err := c.TransferDomain(*op, domain, *period, "y", *auth, extData)
 	fatalif(err)
 
 	color.Printf("@{g}Domain %s %s operation successful!\n", domain, *op)
 	if res != nil {
+		printFeeData(&res.Fee)
 		fmt.Printf("Status: %s\n", res.Status)
 		if !res.REDate.IsZero() {
 			fmt.Printf("Requested: %s by %s\n", res.REDate.Format(time.RFC3339), res.REID)
@@ -759,6 +1099,33 @@ func runTransferDomain(c *epp.Conn, args []string) {
 	}
 }
 
+func runTransferContact(c *epp.Conn, args []string) {
+	fs := flag.NewFlagSet("transfer contact", flag.ExitOnError)
+	op := fs.String("op", "query", "transfer operation (query, request, approve, reject, cancel)")
+	auth := fs.String("auth", "", "auth info")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: epp transfer contact [-op op] [-auth code] <contact-id>")
+		os.Exit(1)
+	}
+
+	id := fs.Arg(0)
+	res, err := c.TransferContact(*op, id, *auth)
+	fatalif(err)
+
+	color.Printf("@{g}Contact %s %s operation successful!\n", id, *op)
+	if res != nil {
+		fmt.Printf("Status: %s\n", res.Status)
+		if !res.REDate.IsZero() {
+			fmt.Printf("Requested: %s by %s\n", res.REDate.Format(time.RFC3339), res.REID)
+		}
+		if !res.ACDate.IsZero() {
+			fmt.Printf("Acted: %s by %s\n", res.ACDate.Format(time.RFC3339), res.ACID)
+		}
+	}
+}
+
 func runRaw(c *epp.Conn, args []string) {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: epp raw <file>")
@@ -808,6 +1175,16 @@ func runUpdateDomain(c *epp.Conn, args []string) {
 	registrant := fs.String("chg-registrant", "", "new registrant ID")
 	auth := fs.String("chg-auth", "", "new auth info")
 
+	// DNSSEC
+	addDS := fs.String("add-ds", "", "comma separated DS records to add (keyTag:alg:digestType:digest)")
+	remDS := fs.String("rem-ds", "", "comma separated DS records to remove")
+	remAllDS := fs.Bool("rem-all-ds", false, "remove all DS records")
+	maxSigLife := fs.Int("max-sig-life", 0, "new DNSSEC maximum signature lifetime in seconds")
+
+	// Launch application
+	phase := fs.String("phase", "", "launch phase, to update a launch application")
+	appID := fs.String("application-id", "", "launch application ID")
+
 	// Contacts
 	addAdmin := fs.String("add-admin", "", "admin contact to add")
 	addTech := fs.String("add-tech", "", "tech contact to add")
@@ -826,66 +1203,52 @@ func runUpdateDomain(c *epp.Conn, args []string) {
 
 	domain := fs.Arg(0)
 
-	add := make(map[string]interface{})
-	rem := make(map[string]interface{})
-	chg := make(map[string]string)
-
-	// NS
+	du := &epp.DomainUpdate{
+		Domain: domain,
+		Add: epp.DomainAddRem{
+			Contacts: parseContacts(*addAdmin, *addTech, *addBilling),
+		},
+		Rem: epp.DomainAddRem{
+			Contacts: parseContacts(*remAdmin, *remTech, *remBilling),
+		},
+		Chg: epp.DomainChange{
+			Registrant: *registrant,
+			AuthInfo:   *auth,
+		},
+	}
 	if *addNS != "" {
-		add["ns"] = parseList(*addNS)
+		du.Add.Nameservers = parseList(*addNS)
 	}
 	if *remNS != "" {
-		rem["ns"] = parseList(*remNS)
+		du.Rem.Nameservers = parseList(*remNS)
 	}
-
-	// Status
 	if *addStatus != "" {
-		add["status"] = parseMap(*addStatus)
+		du.Add.Status = parseStatus(*addStatus)
 	}
 	if *remStatus != "" {
-		rem["status"] = parseMap(*remStatus)
-	}
-
-	// Contacts
-	addContacts := make(map[string]string)
-	if *addAdmin != "" {
-		addContacts["admin"] = *addAdmin
-	}
-	if *addTech != "" {
-		addContacts["tech"] = *addTech
-	}
-	if *addBilling != "" {
-		addContacts["billing"] = *addBilling
-	}
-	if len(addContacts) > 0 {
-		add["contacts"] = addContacts
-	}
-
-	remContacts := make(map[string]string)
-	if *remAdmin != "" {
-		remContacts["admin"] = *remAdmin
-	}
-	if *remTech != "" {
-		remContacts["tech"] = *remTech
+		du.Rem.Status = parseStatus(*remStatus)
 	}
-	if *remBilling != "" {
-		remContacts["billing"] = *remBilling
-	}
-	if len(remContacts) > 0 {
-		rem["contacts"] = remContacts
+	if *addDS != "" || *remDS != "" || *remAllDS || *maxSigLife > 0 {
+		du.SecDNS = &epp.SecDNSUpdate{
+			RemAll:     *remAllDS,
+			MaxSigLife: *maxSigLife,
+		}
+		if *addDS != "" {
+			du.SecDNS.Add.DS = parseDS(*addDS)
+		}
+		if *remDS != "" {
+			du.SecDNS.Rem.DS = parseDS(*remDS)
+		}
 	}
 
-	// Chg
-	if *registrant != "" {
-		chg["registrant"] = *registrant
-	}
-	if *auth != "" {
-		chg["auth"] = *auth
+	if *phase != "" || *appID != "" {
+		du.Launch = &epp.LaunchApplication{Phase: *phase, ApplicationID: *appID}
 	}
 
-	err := c.UpdateDomain(domain, add, rem, chg)
+	res, err := c.DomainUpdate(du)
 	fatalif(err)
 	color.Printf("@{g}Domain %s updated!\n", domain)
+	printFeeData(&res.Fee)
 }
 
 func runUpdateContact(c *epp.Conn, args []string) {
@@ -916,27 +1279,27 @@ func runUpdateContact(c *epp.Conn, args []string) {
 
 	id := fs.Arg(0)
 
-	add := make(map[string]interface{})
-	rem := make(map[string]interface{})
-	chg := make(map[string]interface{})
-
+	cu := &epp.ContactUpdate{
+		ID: id,
+		Chg: epp.ContactChange{
+			Voice:    *voice,
+			Fax:      *fax,
+			Email:    *email,
+			AuthInfo: *auth,
+		},
+	}
 	if *addStatus != "" {
-		add["status"] = parseMap(*addStatus)
+		cu.Add = parseStatus(*addStatus)
 	}
 	if *remStatus != "" {
-		rem["status"] = parseMap(*remStatus)
+		cu.Rem = parseStatus(*remStatus)
 	}
 
 	// Postal change
 	if *name != "" || *org != "" || *street != "" || *city != "" || *sp != "" || *pc != "" || *cc != "" {
-		// This is tricky because we don't know if we need to set existing values or if partial update is allowed by EPP lib helper
-		// Our helper takes PostalInfo.
-		// EPP requires full postal info replacement usually if changing any checking.
-		// But let's assume user provides what they want to change, and we might strictly need all if RFC says so.
-		// RFC 5733 says: <contact:chg> contains <contact:postalInfo>.
-		// Ideally we should fetch existing info first, but that's expensive.
-		// For now let's construct what we have. API consumer should know better.
-		pi := epp.PostalInfo{
+		// RFC 5733 replaces the whole <contact:postalInfo>, so the caller
+		// must supply every field, not just the ones that change.
+		cu.Chg.Postal = []epp.PostalInfo{{
 			Name:   *name,
 			Org:    *org,
 			Street: *street,
@@ -944,24 +1307,10 @@ func runUpdateContact(c *epp.Conn, args []string) {
 			SP:     *sp,
 			PC:     *pc,
 			CC:     *cc,
-		}
-		chg["postal"] = pi
-	}
-
-	if *email != "" {
-		chg["email"] = *email
-	}
-	if *voice != "" {
-		chg["voice"] = *voice
-	}
-	if *fax != "" {
-		chg["fax"] = *fax
-	}
-	if *auth != "" {
-		chg["auth"] = *auth
+		}}
 	}
 
-	err := c.UpdateContact(id, add, rem, chg)
+	_, err := c.ContactUpdate(cu)
 	fatalif(err)
 	color.Printf("@{g}Contact %s updated!\n", id)
 }
@@ -986,35 +1335,30 @@ func runUpdateHost(c *epp.Conn, args []string) {
 
 	host := fs.Arg(0)
 
-	add := make(map[string]interface{})
-	rem := make(map[string]interface{})
-	chg := make(map[string]interface{})
-
+	hu := &epp.HostUpdate{
+		Host:    host,
+		NewName: *newName,
+	}
 	if *addIPs != "" {
-		add["ips"] = parseList(*addIPs)
+		hu.Add.IPv4 = parseList(*addIPs)
 	}
 	if *addV6 != "" {
-		add["v6"] = parseList(*addV6)
+		hu.Add.IPv6 = parseList(*addV6)
 	}
 	if *addStatus != "" {
-		add["status"] = parseMap(*addStatus)
+		hu.Add.Status = parseStatus(*addStatus)
 	}
-
 	if *remIPs != "" {
-		rem["ips"] = parseList(*remIPs)
+		hu.Rem.IPv4 = parseList(*remIPs)
 	}
 	if *remV6 != "" {
-		rem["v6"] = parseList(*remV6)
+		hu.Rem.IPv6 = parseList(*remV6)
 	}
 	if *remStatus != "" {
-		rem["status"] = parseMap(*remStatus)
+		hu.Rem.Status = parseStatus(*remStatus)
 	}
 
-	if *newName != "" {
-		chg["name"] = *newName
-	}
-
-	err := c.UpdateHost(host, add, rem, chg)
+	_, err := c.HostUpdate(hu)
 	fatalif(err)
 	color.Printf("@{g}Host %s updated!\n", host)
 }
@@ -1027,19 +1371,68 @@ func parseList(s string) []string {
 	return parts
 }
 
-func parseMap(s string) map[string]string {
-	m := make(map[string]string)
-	parts := strings.Split(s, ",")
-	for _, p := range parts {
+// parseStatus parses a comma separated list of status=text pairs.
+// The text is optional.
+func parseStatus(s string) []epp.ObjectStatus {
+	var status []epp.ObjectStatus
+	for _, p := range strings.Split(s, ",") {
 		kv := strings.SplitN(p, "=", 2)
-		k := strings.TrimSpace(kv[0])
+		st := epp.ObjectStatus{Status: strings.TrimSpace(kv[0])}
 		if len(kv) == 2 {
-			m[k] = strings.TrimSpace(kv[1])
-		} else {
-			m[k] = "" // No value, e.g. status without message
+			st.Text = strings.TrimSpace(kv[1])
 		}
+		status = append(status, st)
+	}
+	return status
+}
+
+// parseDS parses a comma separated list of keyTag:alg:digestType:digest
+// DS records.
+func parseDS(s string) []epp.DSData {
+	var dss []epp.DSData
+	for _, p := range parseList(s) {
+		f := strings.Split(p, ":")
+		if len(f) != 4 {
+			fatalif(fmt.Errorf("invalid DS record %q, want keyTag:alg:digestType:digest", p))
+		}
+		var ds epp.DSData
+		var err error
+		ds.KeyTag, err = strconv.Atoi(f[0])
+		fatalif(err)
+		ds.Alg, err = strconv.Atoi(f[1])
+		fatalif(err)
+		ds.DigestType, err = strconv.Atoi(f[2])
+		fatalif(err)
+		ds.Digest = f[3]
+		dss = append(dss, ds)
 	}
-	return m
+	return dss
+}
+
+// parseSMD returns the base64 encoded signed mark from the contents of an
+// SMD file, which wraps it in -----BEGIN ENCODED SMD----- and
+// -----END ENCODED SMD----- lines after a readable header (RFC 7848).
+// Contents without the markers are returned trimmed.
+func parseSMD(s string) string {
+	const begin, end = "-----BEGIN ENCODED SMD-----", "-----END ENCODED SMD-----"
+	if i := strings.Index(s, begin); i >= 0 {
+		s = s[i+len(begin):]
+		if j := strings.Index(s, end); j >= 0 {
+			s = s[:j]
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseContacts returns the non-empty admin, tech and billing contacts.
+func parseContacts(admin, tech, billing string) []epp.DomainContact {
+	var contacts []epp.DomainContact
+	for _, dc := range []epp.DomainContact{{Type: "admin", ID: admin}, {Type: "tech", ID: tech}, {Type: "billing", ID: billing}} {
+		if dc.ID != "" {
+			contacts = append(contacts, dc)
+		}
+	}
+	return contacts
 }
 
 func logif(err error) bool {
@@ -1057,6 +1450,19 @@ func fatalif(err error) {
 	}
 }
 
+func printClaims(dcr *epp.DomainCheckResponse) {
+	for _, claim := range dcr.Claims {
+		if !claim.Exists {
+			color.Printf("%-30s @{g}no claims\n", claim.Domain)
+			continue
+		}
+		color.Printf("%-30s @{y}claims exist\n", claim.Domain)
+		for _, key := range claim.ClaimKeys {
+			color.Printf("  @{.}claimKey=%s validator=%s\n", key.Key, key.ValidatorID)
+		}
+	}
+}
+
 func printDCR(dcr *epp.DomainCheckResponse) {
 	if dcr == nil {
 		return
@@ -2,8 +2,12 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -33,3 +37,43 @@ func promptRawXML(buf *bytes.Buffer) {
 		fmt.Fprintln(os.Stderr, "") // Newline
 	}
 }
+
+// logHandler is a slog.Handler for epp.Conn.Logger. It writes the XML of
+// each request and response to x as a pretty-printed block, and other
+// events to events as single lines.
+type logHandler struct {
+	x      io.Writer
+	events io.Writer
+}
+
+func (h *logHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *logHandler) Handle(_ context.Context, r slog.Record) error {
+	var x string
+	var line strings.Builder
+	line.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "xml" {
+			x = a.Value.String()
+		} else {
+			fmt.Fprintf(&line, " %s=%v", a.Key, a.Value)
+		}
+		return true
+	})
+	if x != "" {
+		_, err := fmt.Fprintf(h.x, "%s (pretty-printed)\n%s\n\n", strings.ToUpper(strings.TrimPrefix(r.Message, "epp ")), x)
+		return err
+	}
+	_, err := fmt.Fprintln(h.events, line.String())
+	return err
+}
+
+func (h *logHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *logHandler) WithGroup(string) slog.Handler {
+	return h
+}
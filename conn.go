@@ -2,11 +2,17 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"net"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,7 +26,9 @@ func IgnoreEOF(err error) error {
 }
 
 // Conn represents a single connection to an EPP server.
-// Reads and writes are serialized, so it is safe for concurrent use.
+// It is safe for concurrent use. Requests from multiple goroutines are
+// written back-to-back without waiting for earlier responses, and each
+// response is routed to its caller by client transaction ID (clTRID).
 type Conn struct {
 	// Conn is the underlying net.Conn (usually a TLS connection).
 	net.Conn
@@ -30,9 +38,43 @@ type Conn struct {
 	// a connection is already opened will have no effect.
 	Timeout time.Duration
 
-	// m protects Greeting.
+	// NewTransactionID, if set, generates the client transaction identifier
+	// (clTRID) sent with each command. If nil, DefaultTransactionID is used.
+	// A clTRID attached to a context with WithTransactionID takes precedence.
+	NewTransactionID func() string
+
+	// MaxFrameSize is the largest EPP data unit, in bytes, accepted from
+	// the server. A frame header declaring a larger size leaves the frame
+	// stream out of sync; c is closed and a *DesyncError is returned.
+	// If zero, DefaultMaxFrameSize is used.
+	MaxFrameSize int64
+
+	// UserAgent, if set, identifies the client in the loginSec extension
+	// sent with <login> when the server supports it. If nil, a default
+	// naming this package, the Go version and the platform is sent.
+	UserAgent *UserAgent
+
+	// Logger, if set, receives a structured event for each command, with
+	// the command and object type, transaction IDs, result code, latency
+	// and frame sizes. The XML of each request and response is logged at
+	// debug level, redacted as described for Redact.
+	Logger *slog.Logger
+
+	// Observer, if set, is notified around each command, such as to record
+	// metrics or tracing spans. See Metrics for a Prometheus-compatible
+	// implementation.
+	Observer Observer
+
+	// RateLimiter, if set, delays each command until it is allowed by the
+	// limiter, which can be shared by several connections.
+	RateLimiter *RateLimiter
+
+	// m protects Greeting and clID.
 	m sync.Mutex
 
+	// clID is the client identifier sent with a successful <login>.
+	clID string
+
 	// Greeting holds the last received greeting message from the server,
 	// indicating server name, status, data policy and capabilities.
 	//
@@ -45,7 +87,35 @@ type Conn struct {
 	// mWrite synchronizes connection writes.
 	mWrite sync.Mutex
 
+	// mPending protects pending and reading.
+	mPending sync.Mutex
+
+	// pending holds callers awaiting responses, in the order their
+	// requests were written.
+	pending []*pendingResponse
+
+	// reading is true while a goroutine is reading and dispatching responses.
+	reading bool
+
+	// discard is the number of bytes remaining in a partially read frame.
+	// It is protected by mRead.
+	discard int64
+
+	// broken is set when an interrupted frame leaves c unusable.
+	broken atomic.Bool
+
+	// serverClosed is set with broken when the server ends the session.
+	serverClosed atomic.Bool
+
+	// lastWrite is when a request was last written, in Unix nanoseconds.
+	lastWrite atomic.Int64
+
+	// done is closed by Close.
 	done chan struct{}
+
+	// unusable is closed by Close or when c is broken.
+	unusable     chan struct{}
+	unusableOnce sync.Once
 }
 
 // NewConn initializes an epp.Conn from a net.Conn and performs the EPP
@@ -58,12 +128,20 @@ func NewConn(conn net.Conn) (*Conn, error) {
 // NewTimeoutConn initializes an epp.Conn like NewConn, limiting the duration of network
 // operations on conn using Set(Read|Write)Deadline.
 func NewTimeoutConn(conn net.Conn, timeout time.Duration) (*Conn, error) {
+	return NewConnContext(context.Background(), conn, timeout)
+}
+
+// NewConnContext initializes an epp.Conn like NewTimeoutConn. Reading the
+// initial greeting honors cancellation and deadlines from ctx.
+func NewConnContext(ctx context.Context, conn net.Conn, timeout time.Duration) (*Conn, error) {
 	c := &Conn{
-		Conn:    conn,
-		Timeout: timeout,
-		done:    make(chan struct{}),
+		Conn:     conn,
+		Timeout:  timeout,
+		done:     make(chan struct{}),
+		unusable: make(chan struct{}),
 	}
-	g, err := c.readGreeting()
+	c.lastWrite.Store(time.Now().UnixNano())
+	g, err := c.readGreeting(ctx)
 	if err == nil {
 		c.m.Lock()
 		c.Greeting = g
@@ -81,85 +159,380 @@ func (c *Conn) Close() error {
 	}
 	c.Logout()
 	close(c.done)
-	return c.Conn.Close()
+	c.setUnusable()
+	err := c.Conn.Close()
+	if c.broken.Load() && errors.Is(err, net.ErrClosed) {
+		// Already closed after a read or write error.
+		return nil
+	}
+	return err
 }
 
-// writeRequest writes a single EPP request (x) for writing on c.
-// writeRequest can be called from multiple goroutines.
-func (c *Conn) writeRequest(x []byte) error {
-	c.mWrite.Lock()
-	defer c.mWrite.Unlock()
-	if c.Timeout > 0 {
-		c.Conn.SetWriteDeadline(time.Now().Add(c.Timeout))
+// ErrBroken is returned when an interrupted read or write has left the
+// underlying connection in a state where EPP frames can no longer be
+// reliably delimited. The connection is closed and must be replaced.
+var ErrBroken = errors.New("epp: connection broken by interrupted frame")
+
+// DefaultMaxFrameSize is the largest frame accepted from the server
+// if Conn.MaxFrameSize is not set.
+const DefaultMaxFrameSize = 16 << 20
+
+// maxBufferedFrame is the largest frame read into memory before it is
+// decoded. Larger frames are decoded as they are read, unless the raw
+// bytes are needed for logging or by a caller of Raw.
+const maxBufferedFrame = 64 << 10
+
+// DesyncError is returned when the server sends a frame header with an
+// invalid length, such as one larger than Conn.MaxFrameSize, or a response
+// whose clTRID matches no outstanding request. The frames that follow can
+// no longer be delimited or matched to their requests, so the connection
+// is closed and must be replaced. A DesyncError matches ErrBroken.
+type DesyncError struct {
+	Length uint32 // data unit length declared by the header, including the header
+	Max    int64  // maximum accepted length
+	ClTRID string // unmatched clTRID of the response, if any
+}
+
+func (e *DesyncError) Error() string {
+	if e.ClTRID != "" {
+		return fmt.Sprintf("epp: response for unknown clTRID %q: connection out of sync", e.ClTRID)
+	}
+	if e.Length < 4 {
+		return fmt.Sprintf("epp: invalid frame length %d: connection out of sync", e.Length)
 	}
-	return writeDataUnit(c.Conn, x)
+	return fmt.Sprintf("epp: frame length %d exceeds maximum of %d: connection out of sync", e.Length, e.Max)
 }
 
-// readResponse dequeues and returns a EPP response from c.
-// It returns an error if the EPP response contains an error Result.
-// readResponse can be called from multiple goroutines.
-func (c *Conn) readResponse() (*Response, error) {
-	c.mRead.Lock()
-	defer c.mRead.Unlock()
+// Unwrap returns ErrBroken.
+func (e *DesyncError) Unwrap() error {
+	return ErrBroken
+}
+
+// aLongTimeAgo is a non-zero time, far in the past, used to
+// immediately interrupt blocked network operations.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// deadline returns the earliest of the connection timeout and the deadline of ctx.
+// A zero time means no deadline.
+func (c *Conn) deadline(ctx context.Context) time.Time {
+	var t time.Time
 	if c.Timeout > 0 {
-		c.Conn.SetReadDeadline(time.Now().Add(c.Timeout))
+		t = time.Now().Add(c.Timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (t.IsZero() || d.Before(t)) {
+		t = d
+	}
+	return t
+}
+
+// interruptOn arranges for set to be called with a time in the past when ctx
+// is done, interrupting any blocked network operation. The returned func
+// must be called when the operation completes; it waits for the watcher to
+// exit so a late interruption cannot affect a subsequent operation.
+func interruptOn(ctx context.Context, set func(time.Time) error) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			set(aLongTimeAgo)
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// lockContext acquires m, or returns ctx.Err() if ctx is done first.
+func lockContext(ctx context.Context, m *sync.Mutex) error {
+	if ctx.Done() == nil {
+		m.Lock()
+		return nil
+	}
+	if m.TryLock() {
+		return nil
+	}
+	locked := make(chan struct{})
+	go func() {
+		m.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-locked
+			m.Unlock()
+		}()
+		return ctx.Err()
+	}
+}
+
+// ctxErr returns ctx.Err() if ctx is done, otherwise err. A network timeout
+// caused by the deadline of ctx is reported as context.DeadlineExceeded.
+func ctxErr(ctx context.Context, err error) error {
+	if cerr := ctx.Err(); cerr != nil {
+		return cerr
+	}
+	if d, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) && !time.Now().Before(d) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// writeRequest writes a single EPP request (x) for writing on c, adding p
+// to the queue of callers awaiting a response before x is sent.
+// If ctx is done before any byte is written, the request is not sent.
+// If ctx is done part way through the write, the connection is
+// closed and an error wrapping ErrBroken is returned.
+// writeRequest can be called from multiple goroutines.
+func (c *Conn) writeRequest(ctx context.Context, x []byte, p *pendingResponse) error {
+	if err := lockContext(ctx, &c.mWrite); err != nil {
+		return err
+	}
+	defer c.mWrite.Unlock()
+	if c.broken.Load() {
+		return c.brokenErr()
+	}
+	c.enqueue(p)
+	c.Conn.SetWriteDeadline(c.deadline(ctx))
+	stop := interruptOn(ctx, c.Conn.SetWriteDeadline)
+	w := &countWriter{w: c.Conn}
+	err := writeDataUnit(w, x)
+	stop()
+	if err != nil && w.n > 0 && w.n < 4+len(x) {
+		c.markBroken()
+		c.failPending(ErrBroken)
+		return fmt.Errorf("%w: %w", ErrBroken, ctxErr(ctx, err))
 	}
-	n, err := readDataUnitHeader(c.Conn)
 	if err != nil {
-		return nil, err
+		c.dequeue(p)
+		return ctxErr(ctx, err)
 	}
+	c.lastWrite.Store(time.Now().UnixNano())
+	return nil
+}
 
-	// Read the entire body
-	body := make([]byte, n)
-	_, err = io.ReadFull(c.Conn, body)
+// request sends the EPP request x and returns the parsed response.
+// It returns an error if the EPP response contains an error Result.
+// A nil x reads the next response without sending a request.
+// request can be called from multiple goroutines.
+func (c *Conn) request(ctx context.Context, x []byte) (*Response, error) {
+	f, err := c.exchange(ctx, x, false)
 	if err != nil {
 		return nil, err
 	}
+	if f.err != nil {
+		return f.res, f.err
+	}
+	if f.res.Result.IsError() {
+		return f.res, &f.res.Result
+	}
+	return f.res, nil
+}
 
-	logXML("RESPONSE", body)
+// parseResponse decodes an EPP response from body.
+func parseResponse(body []byte) (*Response, error) {
+	res := &Response{}
+	err := IgnoreEOF(scanResponse.Scan(xml.NewDecoder(bytes.NewReader(body)), res))
+	if err != nil {
+		return res, err
+	}
+	res.setTransactionID()
+	res.setPollData()
+	return res, decodeResultValues(body, res)
+}
 
+// parseResponseStream decodes an EPP response as it is read from r.
+// Only the start of the response, holding the <result> elements,
+// is kept in memory.
+func parseResponseStream(r io.Reader) (*Response, error) {
+	head := &headReader{r: r, max: maxBufferedFrame}
 	res := &Response{}
-	// Decode from the body
-	err = IgnoreEOF(scanResponse.Scan(xml.NewDecoder(bytes.NewReader(body)), res))
+	err := IgnoreEOF(scanResponse.Scan(xml.NewDecoder(head), res))
 	if err != nil {
 		return res, err
 	}
-	if res.Result.IsError() {
-		return res, &res.Result
+	res.setTransactionID()
+	res.setPollData()
+	err = decodeResultValues(head.buf.Bytes(), res)
+	if err != nil && head.truncated {
+		// The results did not fit in head; keep the scanned values.
+		err = nil
 	}
 	return res, err
 }
 
-// Raw writes xml to the connection and returns the raw response bytes.
-func (c *Conn) Raw(xml []byte) ([]byte, error) {
-	err := c.writeRequest(xml)
-	if err != nil {
-		return nil, err
+// headReader reads from r, keeping the first max bytes read.
+type headReader struct {
+	r         io.Reader
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (r *headReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	keep := min(n, r.max-r.buf.Len())
+	r.buf.Write(p[:keep])
+	if keep < n {
+		r.truncated = true
 	}
-	return c.ReadRaw()
+	return n, err
 }
 
-// ReadRaw reads a single EPP data unit from c and returns the raw bytes.
-func (c *Conn) ReadRaw() ([]byte, error) {
+// readFrame reads and decodes a single EPP data unit from c.
+// The remainder of a frame left partially read by an earlier timeout is
+// discarded first. Frames larger than maxBufferedFrame are decoded as they
+// are read, without keeping the raw bytes, unless c needs them.
+func (c *Conn) readFrame() (frameResult, error) {
 	c.mRead.Lock()
 	defer c.mRead.Unlock()
-	if c.Timeout > 0 {
-		c.Conn.SetReadDeadline(time.Now().Add(c.Timeout))
+	if c.broken.Load() {
+		return frameResult{}, c.brokenErr()
 	}
-	n, err := readDataUnitHeader(c.Conn)
+	c.Conn.SetReadDeadline(c.deadline(context.Background()))
+
+	if c.discard > 0 {
+		n, err := io.CopyN(io.Discard, c.Conn, c.discard)
+		c.discard -= n
+		if err != nil {
+			return frameResult{}, err
+		}
+		// The discarded frame was the response owed to an abandoned caller.
+		c.dropAbandoned()
+	}
+
+	r := &countReader{r: c.Conn}
+	var size uint32
+	err := binary.Read(r, binary.BigEndian, &size)
 	if err != nil {
-		return nil, err
+		if r.n > 0 {
+			c.markBroken()
+			return frameResult{}, fmt.Errorf("%w: %w", ErrBroken, err)
+		}
+		return frameResult{}, err
+	}
+	if max := c.maxFrameSize(); size < 4 || int64(size) > max {
+		c.markBroken()
+		return frameResult{}, &DesyncError{Length: size, Max: max}
+	}
+
+	// https://tools.ietf.org/html/rfc5734#section-4
+	body := &io.LimitedReader{R: c.Conn, N: int64(size) - 4}
+	var f frameResult
+	if body.N <= maxBufferedFrame || c.wantBody() {
+		// Grow the buffer as data arrives, rather than trusting the header.
+		var buf bytes.Buffer
+		buf.Grow(int(min(body.N, maxBufferedFrame)))
+		_, err = buf.ReadFrom(body)
+		if err == nil && body.N > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			c.discard = body.N
+			return frameResult{}, err
+		}
+		f.body = buf.Bytes()
+		f.size = int(size)
+		logXML("RESPONSE", f.body)
+		c.debugXML(context.Background(), "epp response", f.body)
+		f.res, f.perr = parseResponse(f.body)
+		return f, nil
 	}
 
-	// Read the entire body
-	body := make([]byte, n)
-	_, err = io.ReadFull(c.Conn, body)
+	f.size = int(size)
+	f.res, f.perr = parseResponseStream(body)
+	// Consume whatever the decoder left unread, to stay in sync.
+	_, err = io.Copy(io.Discard, body)
+	if err == nil && body.N > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		c.discard = body.N
+		return frameResult{}, err
+	}
+	return f, nil
+}
+
+// maxFrameSize returns the largest frame accepted from the server.
+func (c *Conn) maxFrameSize() int64 {
+	if c.MaxFrameSize > 0 {
+		return c.MaxFrameSize
+	}
+	return DefaultMaxFrameSize
+}
+
+// markBroken marks c as no longer usable and closes the underlying connection.
+func (c *Conn) markBroken() {
+	c.broken.Store(true)
+	c.Conn.Close()
+	c.setUnusable()
+}
+
+// setUnusable closes c.unusable, once.
+func (c *Conn) setUnusable() {
+	c.unusableOnce.Do(func() {
+		if c.unusable != nil {
+			close(c.unusable)
+		}
+	})
+}
+
+// Raw writes xml to the connection and returns the raw response bytes.
+// A <clTRID> is added to xml if it is a command without one.
+func (c *Conn) Raw(xml []byte) ([]byte, error) {
+	return c.RawContext(context.Background(), xml)
+}
+
+// RawContext is like Raw, but honors cancellation and deadlines from ctx.
+func (c *Conn) RawContext(ctx context.Context, xml []byte) ([]byte, error) {
+	f, err := c.exchange(ctx, xml, true)
 	if err != nil {
 		return nil, err
 	}
+	return f.body, nil
+}
+
+// ReadRaw reads a single EPP data unit from c and returns the raw bytes.
+func (c *Conn) ReadRaw() ([]byte, error) {
+	return c.ReadRawContext(context.Background())
+}
+
+// ReadRawContext is like ReadRaw, but honors cancellation and deadlines from ctx.
+func (c *Conn) ReadRawContext(ctx context.Context) ([]byte, error) {
+	return c.RawContext(ctx, nil)
+}
+
+// countWriter counts bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += n
+	return n, err
+}
+
+// countReader counts bytes read from r.
+type countReader struct {
+	r io.Reader
+	n int
+}
 
-	logXML("RESPONSE", body)
-	return body, nil
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
 }
 
 // writeDataUnit writes x to w.
@@ -2,9 +2,15 @@ package epp
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -68,6 +74,221 @@ func TestNewConn(t *testing.T) {
 	st.Expect(t, err, nil)
 }
 
+// readTestRequest reads and returns a single EPP data unit sent by a client.
+func readTestRequest(r io.Reader) ([]byte, error) {
+	n, err := readDataUnitHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	_, err = io.ReadFull(r, buf)
+	return buf, err
+}
+
+func TestConnContextCanceledBeforeWrite(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		// The canceled request must never arrive; the next one must.
+		x, err := readTestRequest(conn)
+		st.Expect(t, err, nil)
+		st.Expect(t, string(x), "<second/>")
+		writeDataUnit(conn, []byte("second"))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.RawContext(ctx, []byte("<first/>"))
+	st.Expect(t, err, context.Canceled)
+
+	res, err := c.Raw([]byte("<second/>"))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(res), "second")
+}
+
+func TestConnContextDeadlineDuringRead(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	release := make(chan struct{})
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		<-release
+		writeDataUnit(conn, []byte("first"))
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte("second"))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = c.RawContext(ctx, []byte("<first/>"))
+	st.Expect(t, err, context.DeadlineExceeded)
+	close(release)
+
+	// The late response to the abandoned request is discarded.
+	res, err := c.Raw([]byte("<second/>"))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(res), "second")
+}
+
+func TestConnContextCanceledMidFrame(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	release := make(chan struct{})
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		var frame bytes.Buffer
+		writeDataUnit(&frame, []byte("first response"))
+		conn.Write(frame.Bytes()[:8])
+		<-release
+		conn.Write(frame.Bytes()[8:])
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte("second"))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err = c.RawContext(ctx, []byte("<first/>"))
+	st.Expect(t, err, context.Canceled)
+	close(release)
+
+	res, err := c.Raw([]byte("<second/>"))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(res), "second")
+}
+
+func TestConnMaxFrameSize(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		// A corrupted header claiming nearly 4 GiB.
+		conn.Write([]byte{0xff, 0xff, 0xff, 0xf0})
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	c.MaxFrameSize = 1 << 20
+
+	err = c.Hello()
+	var de *DesyncError
+	st.Expect(t, errors.As(err, &de), true)
+	st.Expect(t, de.Length, uint32(0xfffffff0))
+	st.Expect(t, de.Max, int64(1<<20))
+	st.Expect(t, errors.Is(err, ErrBroken), true)
+
+	// The session can no longer be used.
+	_, err = c.Raw([]byte("<hello/>"))
+	st.Expect(t, errors.Is(err, ErrBroken), true)
+}
+
+func TestConnInvalidFrameLength(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		conn.Write([]byte{0, 0, 0, 2})
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	_, err = c.Raw([]byte("<hello/>"))
+	var de *DesyncError
+	st.Expect(t, errors.As(err, &de), true)
+	st.Expect(t, de.Length, uint32(2))
+	st.Expect(t, err.Error(), "epp: invalid frame length 2: connection out of sync")
+}
+
+func TestConnStreamsLargeFrames(t *testing.T) {
+	var check strings.Builder
+	check.WriteString(`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="2306"><msg>Parameter value policy error</msg><value><domain:name xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">example.com</domain:name></value></result>`)
+	check.WriteString(`<resData><domain:chkData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&check, `<domain:cd><domain:name avail="1">example-%d.com</domain:name></domain:cd>`, i)
+	}
+	check.WriteString(`</domain:chkData></resData><trID><clTRID>ABC-12345</clTRID></trID></response></epp>`)
+	x := check.String()
+	st.Assert(t, len(x) > maxBufferedFrame, true)
+
+	res, err := parseResponseStream(strings.NewReader(x))
+	st.Expect(t, err, nil)
+	st.Expect(t, len(res.DomainCheckResponse.Checks), 2000)
+	st.Expect(t, res.DomainCheckResponse.Checks[1999].Domain, "example-1999.com")
+	st.Expect(t, res.Result.Values, []string{`<domain:name xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">example.com</domain:name>`})
+
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		for i := 0; i < 2; i++ {
+			readTestRequest(conn)
+			writeDataUnit(conn, []byte(x))
+		}
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	c.NewTransactionID = testTransactionID
+
+	dcr, err := c.CheckDomain("example.com")
+	var r *Result
+	st.Assert(t, errors.As(err, &r), true)
+	st.Expect(t, r.Code, 2306)
+	st.Expect(t, r.Values, []string{`<domain:name xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">example.com</domain:name>`})
+	st.Expect(t, dcr, (*DomainCheckResponse)(nil))
+
+	// Raw callers still receive the whole frame.
+	body, err := c.Raw([]byte(xmlCommandPrefix + "<check/>" + xmlCommandSuffix))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(body), x)
+}
+
 func TestDeleteRange(t *testing.T) {
 	v := deleteRange([]byte(`<foo><bar><baz></baz></bar></foo>`), []byte(`<baz`), []byte(`</baz>`))
 	st.Expect(t, string(v), `<foo><bar></bar></foo>`)
@@ -2,6 +2,7 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"time"
 
@@ -10,27 +11,44 @@ import (
 
 // PostalInfo represents the postal address information for a contact.
 type PostalInfo struct {
-	Name   string // <contact:name>
-	Org    string // <contact:org> (optional)
-	Street string // <contact:street> (optional, up to 3)
-	City   string // <contact:city>
-	SP     string // <contact:sp> (optional)
-	PC     string // <contact:pc> (optional)
-	CC     string // <contact:cc>
+	Type    string // type attribute: "int" or "loc"; defaults to "int"
+	Name    string // <contact:name>
+	Org     string // <contact:org> (optional)
+	Street  string // <contact:street> (optional)
+	Street2 string // second <contact:street> (optional)
+	Street3 string // third <contact:street> (optional)
+	City    string // <contact:city>
+	SP      string // <contact:sp> (optional)
+	PC      string // <contact:pc> (optional)
+	CC      string // <contact:cc>
+}
+
+// Disclose represents a <contact:disclose> element, identifying contact
+// fields that the server should or should not disclose, depending on Flag.
+// https://tools.ietf.org/html/rfc5733#section-2.9
+type Disclose struct {
+	Flag  bool     // flag attribute
+	Name  []string // <contact:name type="...">, "int" or "loc"
+	Org   []string // <contact:org type="...">
+	Addr  []string // <contact:addr type="...">
+	Voice bool     // <contact:voice/>
+	Fax   bool     // <contact:fax/>
+	Email bool     // <contact:email/>
 }
 
 // CreateContact requests the creation of a contact.
 // https://tools.ietf.org/html/rfc5733#section-3.2.1
 func (c *Conn) CreateContact(id string, email string, pi PostalInfo, voice string, auth string, extData map[string]string) (*ContactCreateResponse, error) {
+	return c.CreateContactContext(context.Background(), id, email, pi, voice, auth, extData)
+}
+
+// CreateContactContext is like CreateContact, but honors cancellation and deadlines from ctx.
+func (c *Conn) CreateContactContext(ctx context.Context, id string, email string, pi PostalInfo, voice string, auth string, extData map[string]string) (*ContactCreateResponse, error) {
 	x, err := encodeContactCreate(&c.Greeting, id, email, pi, voice, auth, extData)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +89,7 @@ func encodeContactCreate(greeting *Greeting, id string, email string, pi PostalI
 type ContactCreateResponse struct {
 	ID     string    // <contact:id>
 	CrDate time.Time // <contact:crDate>
+	TransactionID
 }
 
 func init() {
@@ -87,3 +106,70 @@ func init() {
 		return err
 	})
 }
+
+// CheckContact queries the EPP server for the availability status of one or more contact IDs.
+// https://tools.ietf.org/html/rfc5733#section-3.1.1
+func (c *Conn) CheckContact(ids ...string) (*ContactCheckResponse, error) {
+	return c.CheckContactContext(context.Background(), ids...)
+}
+
+// CheckContactContext is like CheckContact, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckContactContext(ctx context.Context, ids ...string) (*ContactCheckResponse, error) {
+	x, err := encodeContactCheck(ids)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactCheckResponse, nil
+}
+
+func encodeContactCheck(ids []string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<check><contact:check xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	for _, id := range ids {
+		buf.WriteString(`<contact:id>`)
+		xml.EscapeText(buf, []byte(id))
+		buf.WriteString(`</contact:id>`)
+	}
+	buf.WriteString(`</contact:check></check>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// ContactCheckResponse represents an EPP <response> for a contact check.
+type ContactCheckResponse struct {
+	Checks []ContactCheck
+	TransactionID
+}
+
+// ContactCheck represents a <contact:cd> element in an EPP <chkData>.
+type ContactCheck struct {
+	ID        string
+	Reason    string
+	Available bool
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjContact + " chkData"
+	scanResponse.MustHandleStartElement(path+">cd", func(c *xx.Context) error {
+		ccr := &c.Value.(*Response).ContactCheckResponse
+		ccr.Checks = append(ccr.Checks, ContactCheck{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>id", func(c *xx.Context) error {
+		checks := c.Value.(*Response).ContactCheckResponse.Checks
+		check := &checks[len(checks)-1]
+		check.ID = string(c.CharData)
+		check.Available = c.AttrBool("", "avail")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>reason", func(c *xx.Context) error {
+		checks := c.Value.(*Response).ContactCheckResponse.Checks
+		check := &checks[len(checks)-1]
+		check.Reason = string(c.CharData)
+		return nil
+	})
+}
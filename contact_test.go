@@ -29,3 +29,67 @@ func TestEncodeContactCreate(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestEncodeContactCreateLocalStreets(t *testing.T) {
+	pi := PostalInfo{
+		Type:    "loc",
+		Name:    "John Doe",
+		Street:  "123 Main St",
+		Street2: "Suite 100",
+		Street3: "Building B",
+		City:    "New York",
+		CC:      "US",
+	}
+	x, err := encodeContactCreate(nil, "contact123", "john@example.com", pi, "", "", nil)
+	st.Expect(t, err, nil)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><contact:create xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>contact123</contact:id><contact:postalInfo type="loc"><contact:name>John Doe</contact:name><contact:addr><contact:street>123 Main St</contact:street><contact:street>Suite 100</contact:street><contact:street>Building B</contact:street><contact:city>New York</contact:city><contact:cc>US</contact:cc></contact:addr></contact:postalInfo><contact:email>john@example.com</contact:email></contact:create></create></command></epp>`
+	st.Expect(t, string(x), expected)
+}
+
+func TestEncodeContactCheck(t *testing.T) {
+	x, err := encodeContactCheck([]string{"sh8013", "sah8013"})
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><contact:check xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:id>sah8013</contact:id></contact:check></check></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestScanContactCheckResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <contact:chkData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+        <contact:cd>
+          <contact:id avail="1">sh8013</contact:id>
+        </contact:cd>
+        <contact:cd>
+          <contact:id avail="0">sah8013</contact:id>
+          <contact:reason>In use</contact:reason>
+        </contact:cd>
+      </contact:chkData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ContactCheckResponse.Checks, []ContactCheck{
+		{ID: "sh8013", Available: true},
+		{ID: "sah8013", Reason: "In use"},
+	})
+}
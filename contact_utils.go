@@ -7,7 +7,13 @@ import (
 
 func encodePostalInfo(buf *bytes.Buffer, pi *PostalInfo) {
 	// Postal Info "int" (international) is standard
-	buf.WriteString(`<contact:postalInfo type="int">`)
+	typ := pi.Type
+	if typ == "" {
+		typ = "int"
+	}
+	buf.WriteString(`<contact:postalInfo type="`)
+	xml.EscapeText(buf, []byte(typ))
+	buf.WriteString(`">`)
 	buf.WriteString(`<contact:name>`)
 	xml.EscapeText(buf, []byte(pi.Name))
 	buf.WriteString(`</contact:name>`)
@@ -19,10 +25,12 @@ func encodePostalInfo(buf *bytes.Buffer, pi *PostalInfo) {
 	}
 
 	buf.WriteString(`<contact:addr>`)
-	if pi.Street != "" {
-		buf.WriteString(`<contact:street>`)
-		xml.EscapeText(buf, []byte(pi.Street))
-		buf.WriteString(`</contact:street>`)
+	for _, street := range []string{pi.Street, pi.Street2, pi.Street3} {
+		if street != "" {
+			buf.WriteString(`<contact:street>`)
+			xml.EscapeText(buf, []byte(street))
+			buf.WriteString(`</contact:street>`)
+		}
 	}
 	buf.WriteString(`<contact:city>`)
 	xml.EscapeText(buf, []byte(pi.City))
@@ -45,3 +53,33 @@ func encodePostalInfo(buf *bytes.Buffer, pi *PostalInfo) {
 	buf.WriteString(`</contact:addr>`)
 	buf.WriteString(`</contact:postalInfo>`)
 }
+
+// encodeDisclose writes a <contact:disclose> element.
+// https://tools.ietf.org/html/rfc5733#section-2.9
+func encodeDisclose(buf *bytes.Buffer, d *Disclose) {
+	flag := "0"
+	if d.Flag {
+		flag = "1"
+	}
+	buf.WriteString(`<contact:disclose flag="` + flag + `">`)
+	for _, f := range []struct {
+		name  string
+		types []string
+	}{{"name", d.Name}, {"org", d.Org}, {"addr", d.Addr}} {
+		for _, typ := range f.types {
+			buf.WriteString(`<contact:` + f.name + ` type="`)
+			xml.EscapeText(buf, []byte(typ))
+			buf.WriteString(`"/>`)
+		}
+	}
+	if d.Voice {
+		buf.WriteString(`<contact:voice/>`)
+	}
+	if d.Fax {
+		buf.WriteString(`<contact:fax/>`)
+	}
+	if d.Email {
+		buf.WriteString(`<contact:email/>`)
+	}
+	buf.WriteString(`</contact:disclose>`)
+}
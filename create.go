@@ -2,48 +2,160 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"sort"
 	"time"
 
 	"github.com/nbio/xx"
 )
 
 // CreateDomain requests the creation of a domain.
+// Contact types other than admin, tech and billing return a ValidationError.
 // https://tools.ietf.org/html/rfc5731#section-3.2.1
 func (c *Conn) CreateDomain(domain string, period int, unit string, auth string, registrant string, contacts map[string]string, ns []string, extData map[string]string) (*DomainCreateResponse, error) {
-	x, err := encodeDomainCreate(&c.Greeting, domain, period, unit, auth, registrant, contacts, ns, extData)
-	if err != nil {
-		return nil, err
+	return c.CreateDomainContext(context.Background(), domain, period, unit, auth, registrant, contacts, ns, extData)
+}
+
+// CreateDomainContext is like CreateDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) CreateDomainContext(ctx context.Context, domain string, period int, unit string, auth string, registrant string, contacts map[string]string, ns []string, extData map[string]string) (*DomainCreateResponse, error) {
+	return c.DomainCreateContext(ctx, newDomainCreate(domain, period, unit, auth, registrant, contacts, ns, extData))
+}
+
+// DomainCreate holds the parameters of a domain create command.
+// https://tools.ietf.org/html/rfc5731#section-3.2.1
+type DomainCreate struct {
+	Domain      string          // <domain:name>, required
+	Period      int             // <domain:period>; zero uses the server default
+	Unit        string          // period unit, "y" (default) or "m"
+	Nameservers []string        // <domain:ns><domain:hostObj>
+	Registrant  string          // <domain:registrant>
+	Contacts    []DomainContact // <domain:contact>
+	AuthInfo    string          // <domain:authInfo><domain:pw>
+	SecDNS      *SecDNSData     // optional DNSSEC data (RFC 5910)
+	Launch      *LaunchCreate   // optional launch phase data (RFC 8334)
+
+	AllocationToken string // <allocationToken:allocationToken> (RFC 8495)
+
+	// Extensions holds extension data, keyed as for CreateDomain:
+	//   - "fee:fee" and "fee:currency": the fee to accept
+	//   - "launch:phase": the launch phase, if Launch is nil
+	//   - "allocationToken:allocationToken": the allocation token, if AllocationToken is empty
+	Extensions map[string]string
+}
+
+// Validate returns a ValidationError if dc is not a valid domain create command.
+func (dc *DomainCreate) Validate() error {
+	if err := validateRequired("Domain", dc.Domain); err != nil {
+		return err
 	}
-	err = c.writeRequest(x)
+	if err := validatePeriod(dc.Period, dc.Unit); err != nil {
+		return err
+	}
+	if err := validateHostNames("Nameservers", dc.Nameservers); err != nil {
+		return err
+	}
+	if dc.SecDNS != nil {
+		if err := dc.SecDNS.validate("SecDNS"); err != nil {
+			return err
+		}
+	}
+	if dc.Launch != nil {
+		if err := dc.Launch.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateContacts("Contacts", dc.Contacts)
+}
+
+// DomainCreate requests the creation of a domain described by dc.
+// https://tools.ietf.org/html/rfc5731#section-3.2.1
+func (c *Conn) DomainCreate(dc *DomainCreate) (*DomainCreateResponse, error) {
+	return c.DomainCreateContext(context.Background(), dc)
+}
+
+// DomainCreateContext is like DomainCreate, but honors cancellation and deadlines from ctx.
+func (c *Conn) DomainCreateContext(ctx context.Context, dc *DomainCreate) (*DomainCreateResponse, error) {
+	x, err := dc.encode(&c.Greeting)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
 	return &res.DomainCreateResponse, nil
 }
 
+// newDomainCreate converts the arguments of CreateDomain to a DomainCreate.
+func newDomainCreate(domain string, period int, unit string, auth string, registrant string, contacts map[string]string, ns []string, extData map[string]string) *DomainCreate {
+	return &DomainCreate{
+		Domain:      domain,
+		Period:      period,
+		Unit:        unit,
+		Nameservers: ns,
+		Registrant:  registrant,
+		Contacts:    domainContacts(contacts),
+		AuthInfo:    auth,
+		Extensions:  extData,
+	}
+}
+
+// domainContacts converts a map of contact type to ID to a slice,
+// ordered admin, tech, billing. Other types follow in sorted order,
+// for validation to reject.
+func domainContacts(contacts map[string]string) []DomainContact {
+	var dcs []DomainContact
+	for _, typ := range []string{"admin", "tech", "billing"} {
+		if id, ok := contacts[typ]; ok {
+			dcs = append(dcs, DomainContact{Type: typ, ID: id})
+		}
+	}
+	var other []string
+	for typ := range contacts {
+		switch typ {
+		case "admin", "tech", "billing":
+		default:
+			other = append(other, typ)
+		}
+	}
+	sort.Strings(other)
+	for _, typ := range other {
+		dcs = append(dcs, DomainContact{Type: typ, ID: contacts[typ]})
+	}
+	return dcs
+}
+
 func encodeDomainCreate(greeting *Greeting, domain string, period int, unit string, auth string, registrant string, contacts map[string]string, ns []string, extData map[string]string) ([]byte, error) {
+	return newDomainCreate(domain, period, unit, auth, registrant, contacts, ns, extData).encode(greeting)
+}
+
+func (dc *DomainCreate) encode(greeting *Greeting) ([]byte, error) {
+	if err := dc.Validate(); err != nil {
+		return nil, err
+	}
+	unit := dc.Unit
+	if unit == "" {
+		unit = "y"
+	}
+
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
 	buf.WriteString(`<domain:name>`)
-	xml.EscapeText(buf, []byte(domain))
+	xml.EscapeText(buf, []byte(dc.Domain))
 	buf.WriteString(`</domain:name>`)
 
-	if period > 0 {
+	if dc.Period > 0 {
 		buf.WriteString(`<domain:period unit="`)
 		buf.WriteString(unit)
 		buf.WriteString(`">`)
-		buf.WriteString(xmlInt(period))
+		buf.WriteString(xmlInt(dc.Period))
 		buf.WriteString(`</domain:period>`)
 	}
 
-	if len(ns) > 0 {
+	if len(dc.Nameservers) > 0 {
 		buf.WriteString(`<domain:ns>`)
-		for _, host := range ns {
+		for _, host := range dc.Nameservers {
 			buf.WriteString(`<domain:hostObj>`)
 			xml.EscapeText(buf, []byte(host))
 			buf.WriteString(`</domain:hostObj>`)
@@ -51,61 +163,50 @@ func encodeDomainCreate(greeting *Greeting, domain string, period int, unit stri
 		buf.WriteString(`</domain:ns>`)
 	}
 
-	if registrant != "" {
+	if dc.Registrant != "" {
 		buf.WriteString(`<domain:registrant>`)
-		xml.EscapeText(buf, []byte(registrant))
+		xml.EscapeText(buf, []byte(dc.Registrant))
 		buf.WriteString(`</domain:registrant>`)
 	}
 
-	for _, result := range []string{"admin", "tech", "billing"} {
-		if id, ok := contacts[result]; ok {
-			buf.WriteString(`<domain:contact type="`)
-			buf.WriteString(result)
-			buf.WriteString(`">`)
-			xml.EscapeText(buf, []byte(id))
-			buf.WriteString(`</domain:contact>`)
-		}
-	}
+	encodeDomainContacts(buf, dc.Contacts)
 
-	if auth != "" {
+	if dc.AuthInfo != "" {
 		buf.WriteString(`<domain:authInfo><domain:pw>`)
-		xml.EscapeText(buf, []byte(auth))
+		xml.EscapeText(buf, []byte(dc.AuthInfo))
 		buf.WriteString(`</domain:pw></domain:authInfo>`)
 	}
 
 	buf.WriteString(`</domain:create></create>`)
 
 	// Extensions
-	fee, hasFee := extData["fee:fee"]
-	phase, hasLaunch := extData["launch:phase"]
-	hasExtension := hasFee || hasLaunch
+	extData := dc.Extensions
+	_, hasFee := extData["fee:fee"]
+	launch := dc.Launch
+	if phase, ok := extData["launch:phase"]; ok && launch == nil {
+		launch = &LaunchCreate{Phase: phase}
+	}
+	token := dc.AllocationToken
+	if token == "" {
+		token = extData[AllocationTokenKey]
+	}
+	hasExtension := hasFee || launch != nil || dc.SecDNS != nil || token != ""
 
 	if hasExtension {
 		buf.WriteString(`<extension>`)
 
-		if hasFee {
-			buf.WriteString(`<fee:create xmlns:fee="`)
-			buf.WriteString(ExtFee10)
-			buf.WriteString(`">`)
-			if currency, ok := extData["fee:currency"]; ok {
-				buf.WriteString(`<fee:currency>`)
-				buf.WriteString(currency)
-				buf.WriteString(`</fee:currency>`)
-			}
-			buf.WriteString(`<fee:fee>`)
-			buf.WriteString(fee)
-			buf.WriteString(`</fee:fee>`)
-			buf.WriteString(`</fee:create>`)
+		if dc.SecDNS != nil {
+			encodeSecDNSCreate(buf, dc.SecDNS)
+		}
+
+		encodeFee(buf, greeting, "create", extData)
+
+		if launch != nil {
+			encodeLaunchCreate(buf, launch)
 		}
 
-		if hasLaunch {
-			buf.WriteString(`<launch:create xmlns:launch="`)
-			buf.WriteString(ExtLaunch)
-			buf.WriteString(`">`)
-			buf.WriteString(`<launch:phase>`)
-			xml.EscapeText(buf, []byte(phase))
-			buf.WriteString(`</launch:phase>`)
-			buf.WriteString(`</launch:create>`)
+		if token != "" {
+			encodeAllocationToken(buf, token)
 		}
 
 		buf.WriteString(`</extension>`)
@@ -115,25 +216,42 @@ func encodeDomainCreate(greeting *Greeting, domain string, period int, unit stri
 	return buf.Bytes(), nil
 }
 
+// encodeDomainContacts writes a <domain:contact> element for each contact.
+func encodeDomainContacts(buf *bytes.Buffer, contacts []DomainContact) {
+	for _, contact := range contacts {
+		buf.WriteString(`<domain:contact type="`)
+		buf.WriteString(contact.Type)
+		buf.WriteString(`">`)
+		xml.EscapeText(buf, []byte(contact.ID))
+		buf.WriteString(`</domain:contact>`)
+	}
+}
+
 // DomainCreateResponse represents an EPP response for a domain create request.
 type DomainCreateResponse struct {
 	Domain string    // <domain:name>
 	CrDate time.Time // <domain:crDate>
 	ExDate time.Time // <domain:exDate>
+
+	Fee           FeeData // <fee:creData>
+	LaunchPhase   string  // <launch:creData><launch:phase>
+	ApplicationID string  // <launch:creData><launch:applicationID>
+	TransactionID
 }
 
 // CreateHost requests the creation of a host.
 // https://tools.ietf.org/html/rfc5732#section-3.2.1
 func (c *Conn) CreateHost(host string, ips []string, v6 []string) (*HostCreateResponse, error) {
+	return c.CreateHostContext(context.Background(), host, ips, v6)
+}
+
+// CreateHostContext is like CreateHost, but honors cancellation and deadlines from ctx.
+func (c *Conn) CreateHostContext(ctx context.Context, host string, ips []string, v6 []string) (*HostCreateResponse, error) {
 	x, err := encodeHostCreate(&c.Greeting, host, ips, v6)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -167,6 +285,7 @@ func encodeHostCreate(greeting *Greeting, host string, ips []string, v6 []string
 type HostCreateResponse struct {
 	Host   string    // <host:name>
 	CrDate time.Time // <host:crDate>
+	TransactionID
 }
 
 func init() {
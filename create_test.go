@@ -2,6 +2,7 @@ package epp
 
 import (
 	"encoding/xml"
+	"errors"
 	"testing"
 
 	"github.com/nbio/st"
@@ -97,3 +98,41 @@ func TestEncodeHostCreate(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestDomainCreateEncode(t *testing.T) {
+	dc := &DomainCreate{
+		Domain:      "example.com",
+		Period:      6,
+		Unit:        "m",
+		Nameservers: []string{"ns1.example.com"},
+		Registrant:  "regID",
+		Contacts:    []DomainContact{{Type: "tech", ID: "techID"}, {Type: "tech", ID: "techID2"}},
+	}
+	x, err := dc.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="m">6</domain:period><domain:ns><domain:hostObj>ns1.example.com</domain:hostObj></domain:ns><domain:registrant>regID</domain:registrant><domain:contact type="tech">techID</domain:contact><domain:contact type="tech">techID2</domain:contact></domain:create></create></command></epp>`)
+}
+
+func TestDomainCreateValidate(t *testing.T) {
+	tests := []struct {
+		dc    DomainCreate
+		field string
+	}{
+		{DomainCreate{}, "Domain"},
+		{DomainCreate{Domain: "example.com", Period: 100}, "Period"},
+		{DomainCreate{Domain: "example.com", Period: 1, Unit: "d"}, "Unit"},
+		{DomainCreate{Domain: "example.com", Nameservers: []string{""}}, "Nameservers"},
+		{DomainCreate{Domain: "example.com", Contacts: []DomainContact{{Type: "owner", ID: "c1"}}}, "Contacts"},
+		{DomainCreate{Domain: "example.com", Contacts: []DomainContact{{Type: "admin"}}}, "Contacts"},
+		{*newDomainCreate("example.com", 1, "y", "", "", map[string]string{"admin": "c1", "owner": "c2"}, nil, nil), "Contacts"},
+	}
+	for _, tt := range tests {
+		_, err := tt.dc.encode(nil)
+		var verr *ValidationError
+		st.Assert(t, errors.As(err, &verr), true)
+		st.Expect(t, verr.Field, tt.field)
+	}
+	dc := DomainCreate{Domain: "example.com", Period: 1, Contacts: []DomainContact{{Type: "admin", ID: "c1"}}}
+	st.Expect(t, dc.Validate(), nil)
+}
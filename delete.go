@@ -2,22 +2,28 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 )
 
 // DeleteDomain requests the deletion of a domain.
+// It returns the transaction identifiers of the response.
 // https://tools.ietf.org/html/rfc5731#section-3.2.1
-func (c *Conn) DeleteDomain(domain string, extData map[string]string) error {
+func (c *Conn) DeleteDomain(domain string, extData map[string]string) (*TransactionID, error) {
+	return c.DeleteDomainContext(context.Background(), domain, extData)
+}
+
+// DeleteDomainContext is like DeleteDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) DeleteDomainContext(ctx context.Context, domain string, extData map[string]string) (*TransactionID, error) {
 	x, err := encodeDomainDelete(&c.Greeting, domain, extData)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = c.writeRequest(x)
+	res, err := c.request(ctx, x)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.readResponse()
-	return err
+	return &res.Result.TransactionID, nil
 }
 
 func encodeDomainDelete(greeting *Greeting, domain string, extData map[string]string) ([]byte, error) {
@@ -30,18 +36,23 @@ func encodeDomainDelete(greeting *Greeting, domain string, extData map[string]st
 }
 
 // DeleteContact requests the deletion of a contact.
+// It returns the transaction identifiers of the response.
 // https://tools.ietf.org/html/rfc5733#section-3.2.2
-func (c *Conn) DeleteContact(id string, extData map[string]string) error {
+func (c *Conn) DeleteContact(id string, extData map[string]string) (*TransactionID, error) {
+	return c.DeleteContactContext(context.Background(), id, extData)
+}
+
+// DeleteContactContext is like DeleteContact, but honors cancellation and deadlines from ctx.
+func (c *Conn) DeleteContactContext(ctx context.Context, id string, extData map[string]string) (*TransactionID, error) {
 	x, err := encodeContactDelete(&c.Greeting, id, extData)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = c.writeRequest(x)
+	res, err := c.request(ctx, x)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.readResponse()
-	return err
+	return &res.Result.TransactionID, nil
 }
 
 func encodeContactDelete(greeting *Greeting, id string, extData map[string]string) ([]byte, error) {
@@ -54,18 +65,23 @@ func encodeContactDelete(greeting *Greeting, id string, extData map[string]strin
 }
 
 // DeleteHost requests the deletion of a host.
+// It returns the transaction identifiers of the response.
 // https://tools.ietf.org/html/rfc5732#section-3.2.2
-func (c *Conn) DeleteHost(host string) error {
+func (c *Conn) DeleteHost(host string) (*TransactionID, error) {
+	return c.DeleteHostContext(context.Background(), host)
+}
+
+// DeleteHostContext is like DeleteHost, but honors cancellation and deadlines from ctx.
+func (c *Conn) DeleteHostContext(ctx context.Context, host string) (*TransactionID, error) {
 	x, err := encodeHostDelete(&c.Greeting, host)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = c.writeRequest(x)
+	res, err := c.request(ctx, x)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.readResponse()
-	return err
+	return &res.Result.TransactionID, nil
 }
 
 func encodeHostDelete(greeting *Greeting, host string) ([]byte, error) {
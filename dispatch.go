@@ -0,0 +1,275 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// pendingResponse is a caller awaiting the response to a request.
+type pendingResponse struct {
+	// clTRID is the client transaction ID sent with the request, if any.
+	clTRID string
+
+	// raw is set if the caller needs the raw bytes of the response.
+	raw bool
+
+	// ch receives the response. It is buffered so delivery never blocks.
+	ch chan frameResult
+
+	// abandoned is set when the caller stops waiting. The response is still
+	// owed by the server, so the entry is kept to absorb it.
+	// It is protected by Conn.mPending.
+	abandoned bool
+}
+
+// frameResult is a response frame delivered to a pendingResponse.
+type frameResult struct {
+	body []byte    // raw response, unless decoded as it was read
+	size int       // size of the frame, including its header
+	res  *Response // parsed response, if the frame is valid EPP
+	perr error     // error parsing the frame
+	err  error     // error reading the frame
+}
+
+// exchange writes the request x, if not nil, and waits for its response.
+// Responses are read by a single goroutine, which routes each response to
+// the caller whose request carried the same clTRID, falling back to the
+// oldest outstanding request for responses without one. A response with
+// an unknown clTRID breaks the connection.
+// If ctx is done before the response arrives, exchange returns ctx.Err()
+// and the response is discarded when it is received.
+// If raw is set, the raw bytes of the response are returned in body.
+func (c *Conn) exchange(ctx context.Context, x []byte, raw bool) (frameResult, error) {
+	if err := ctx.Err(); err != nil {
+		return frameResult{}, err
+	}
+	p := &pendingResponse{ch: make(chan frameResult, 1), raw: raw}
+	if x == nil {
+		if c.broken.Load() {
+			return frameResult{}, c.brokenErr()
+		}
+		c.enqueue(p)
+		return c.await(ctx, p)
+	}
+
+	if needsTransactionID(x) {
+		x = insertTransactionID(x, c.transactionID(ctx))
+	}
+	p.clTRID = requestTransactionID(x)
+	c.debugXML(ctx, "epp request", x)
+	info := c.newCommandInfo(x)
+	if c.RateLimiter != nil {
+		if err := c.RateLimiter.Wait(ctx, info.Command); err != nil {
+			return frameResult{}, err
+		}
+	}
+	octx := ctx
+	if c.Observer != nil {
+		octx = c.Observer.StartCommand(ctx, info)
+	}
+	info.Start = time.Now()
+	err := c.writeRequest(ctx, x, p)
+	var f frameResult
+	if err == nil {
+		f, err = c.await(ctx, p)
+	} else if c.RateLimiter != nil {
+		c.RateLimiter.unsent(info.Command)
+	}
+	info.Latency = time.Since(info.Start)
+	info.ResponseSize = f.size
+	ferr := err
+	if f.res != nil {
+		info.Result = &f.res.Result
+	} else if ferr == nil {
+		ferr = f.perr
+	}
+	c.logCommand(ctx, info, ferr)
+	if c.Observer != nil {
+		c.Observer.FinishCommand(octx, info, ferr)
+	}
+	return f, err
+}
+
+// await waits for the response for p.
+func (c *Conn) await(ctx context.Context, p *pendingResponse) (frameResult, error) {
+	select {
+	case f := <-p.ch:
+		return f, f.err
+	case <-ctx.Done():
+	}
+	c.mPending.Lock()
+	p.abandoned = true
+	c.mPending.Unlock()
+	select {
+	case f := <-p.ch:
+		return f, f.err
+	default:
+		return frameResult{}, ctx.Err()
+	}
+}
+
+// enqueue adds p to the queue of callers awaiting a response,
+// starting the dispatch goroutine if it is not running.
+func (c *Conn) enqueue(p *pendingResponse) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	c.pending = append(c.pending, p)
+	if !c.reading {
+		c.reading = true
+		go c.dispatch()
+	}
+}
+
+// dequeue removes p from the queue of callers awaiting a response.
+// It is used when the request for p could not be sent.
+func (c *Conn) dequeue(p *pendingResponse) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for i, q := range c.pending {
+		if q == p {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// failPending delivers err to all callers awaiting a response
+// and clears the queue.
+func (c *Conn) failPending(err error) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for _, p := range c.pending {
+		if !p.abandoned {
+			p.ch <- frameResult{err: err}
+		}
+	}
+	c.pending = nil
+}
+
+// dispatch reads responses from c and delivers them to waiting callers.
+// It exits once no caller is waiting.
+func (c *Conn) dispatch() {
+	for c.waiting() {
+		f, err := c.readFrame()
+		if err != nil && errors.Is(err, os.ErrDeadlineExceeded) && !c.broken.Load() {
+			// The responses are still owed; discard them when they arrive.
+			c.abandonPending(err)
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			// The server closed the connection between frames.
+			c.serverClosed.Store(true)
+			err = fmt.Errorf("%w: %w", ErrServerClosed, err)
+		}
+		if err != nil {
+			c.markBroken()
+			c.failPending(err)
+			continue
+		}
+		if err := c.deliver(f); err != nil {
+			c.markBroken()
+			c.failPending(err)
+			continue
+		}
+		if f.res != nil && f.res.Result.IsFatal() {
+			// The server closes the connection after a 25xx result.
+			c.markServerClosed()
+		}
+	}
+}
+
+// waiting reports whether any caller is awaiting a response.
+// If not, it marks the dispatch goroutine as stopped.
+func (c *Conn) waiting() bool {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for _, p := range c.pending {
+		if !p.abandoned {
+			return true
+		}
+	}
+	c.reading = false
+	return false
+}
+
+// wantBody reports whether the raw bytes of the next response must be kept,
+// because debug logging is enabled or a caller of Raw is waiting.
+func (c *Conn) wantBody() bool {
+	if DebugLogger != nil {
+		return true
+	}
+	if c.Logger != nil && c.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		return true
+	}
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for _, p := range c.pending {
+		if p.raw && !p.abandoned {
+			return true
+		}
+	}
+	return false
+}
+
+// abandonPending delivers err to all callers awaiting a response,
+// keeping their entries in the queue to absorb the late responses.
+func (c *Conn) abandonPending(err error) {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for _, p := range c.pending {
+		if !p.abandoned {
+			p.abandoned = true
+			p.ch <- frameResult{err: err}
+		}
+	}
+}
+
+// dropAbandoned removes the oldest abandoned caller from the queue.
+// It is used when the response it was owed is discarded, after a timeout
+// interrupted the frame, so that the entry does not absorb a later response.
+func (c *Conn) dropAbandoned() {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	for i, p := range c.pending {
+		if p.abandoned {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// deliver routes f to the caller whose request has the same clTRID,
+// or to the oldest outstanding request if the response has no clTRID.
+// It returns a *DesyncError if the clTRID matches no outstanding request,
+// rather than hand the response to another caller.
+func (c *Conn) deliver(f frameResult) error {
+	c.mPending.Lock()
+	defer c.mPending.Unlock()
+	if len(c.pending) == 0 {
+		return nil
+	}
+	i := 0
+	if f.res != nil && f.res.Result.ClTRID != "" {
+		i = -1
+		for j, p := range c.pending {
+			if p.clTRID == f.res.Result.ClTRID {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return &DesyncError{ClTRID: f.res.Result.ClTRID}
+		}
+	}
+	p := c.pending[i]
+	c.pending = append(c.pending[:i], c.pending[i+1:]...)
+	if !p.abandoned {
+		p.ch <- f
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package epp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestConnPipelinedResponsesOutOfOrder(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	const n = 3
+	reClTRID := regexp.MustCompile(`<clTRID>([^<]*)</clTRID>`)
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		// Read all requests before answering any, then answer in reverse order.
+		var ids []string
+		for i := 0; i < n; i++ {
+			x, err := readTestRequest(conn)
+			st.Assert(t, err, nil)
+			ids = append(ids, reClTRID.FindStringSubmatch(string(x))[1])
+		}
+		for i := n - 1; i >= 0; i-- {
+			writeDataUnit(conn, []byte(`<epp><response><result code="1000"><msg>`+ids[i]+`</msg></result><trID><clTRID>`+ids[i]+`</clTRID><svTRID>sv</svTRID></trID></response></epp>`))
+		}
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := DefaultTransactionID()
+			ctx := WithTransactionID(context.Background(), id)
+			res, err := c.request(ctx, []byte(`<epp><command><hello-test/></command></epp>`))
+			st.Expect(t, err, nil)
+			st.Expect(t, res.Result.ClTRID, id)
+			st.Expect(t, res.Result.Message, id)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConnResponsesWithoutClTRID(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte("first"))
+		writeDataUnit(conn, []byte("second"))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	// Responses without a clTRID are delivered in request order.
+	first := make(chan []byte)
+	go func() {
+		res, err := c.Raw([]byte("<first/>"))
+		st.Expect(t, err, nil)
+		first <- res
+	}()
+	for {
+		c.mPending.Lock()
+		queued := len(c.pending)
+		c.mPending.Unlock()
+		if queued > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	res, err := c.Raw([]byte("<second/>"))
+	st.Expect(t, err, nil)
+	st.Expect(t, string(res), "second")
+	st.Expect(t, string(<-first), "first")
+}
+
+func TestConnUnknownClTRID(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte(`<epp><response><result code="1000"><msg>Command completed successfully</msg></result><trID><clTRID>OTHER-1</clTRID><svTRID>sv</svTRID></trID></response></epp>`))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	// A response for another request is not delivered to the caller.
+	_, err = c.CheckDomainContext(WithTransactionID(context.Background(), "ABC-1"), "example.com")
+	var de *DesyncError
+	st.Assert(t, errors.As(err, &de), true)
+	st.Expect(t, de.ClTRID, "OTHER-1")
+	st.Expect(t, errors.Is(err, ErrBroken), true)
+	st.Expect(t, errors.Is(c.Err(), ErrBroken), true)
+}
+
+func TestConnTimeoutMidFrame(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	sent := make(chan struct{})
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		// Send part of the response, then the rest after the client times out.
+		x := []byte(testXMLResultOK)
+		binary.Write(conn, binary.BigEndian, uint32(4+len(x)))
+		conn.Write(x[:10])
+		time.Sleep(300 * time.Millisecond)
+		conn.Write(x[10:])
+		close(sent)
+		// The greeting returned for <hello> has no clTRID.
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte(testXMLGreeting))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewTimeoutConn(nc, 100*time.Millisecond)
+	st.Assert(t, err, nil)
+
+	_, err = c.CheckDomain("example.com")
+	st.Expect(t, errors.Is(err, os.ErrDeadlineExceeded), true)
+	<-sent
+	st.Expect(t, c.Hello(), nil)
+	st.Expect(t, c.Err(), nil)
+}
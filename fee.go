@@ -0,0 +1,142 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+
+	"github.com/nbio/xx"
+)
+
+// feeURN returns the fee extension namespace negotiated with the server,
+// in order of preference, or an empty string if the server supports none.
+func (g *Greeting) feeURN() string {
+	switch {
+	case g.SupportsExtension(ExtFee10):
+		return ExtFee10
+	case g.SupportsExtension(ExtFee21):
+		return ExtFee21
+	case g.SupportsExtension(ExtFee11):
+		return ExtFee11
+	// Versions 0.8-0.9 require the returned class to be "standard" for
+	// non-premium domains
+	case g.SupportsExtension(ExtFee08):
+		return ExtFee08
+	case g.SupportsExtension(ExtFee09):
+		return ExtFee09
+	// Version 0.5 has an attribute premium="1" for premium domains
+	case g.SupportsExtension(ExtFee05):
+		return ExtFee05
+	// Version 0.6 and 0.7 don't have a standard way of detecting premiums,
+	// so instead there must be matching done on class names
+	case g.SupportsExtension(ExtFee06):
+		return ExtFee06
+	case g.SupportsExtension(ExtFee07):
+		return ExtFee07
+	}
+	return ""
+}
+
+// feeURNs lists every supported fee extension namespace.
+var feeURNs = []string{ExtFee05, ExtFee06, ExtFee07, ExtFee08, ExtFee09, ExtFee11, ExtFee21, ExtFee10}
+
+// encodeFee writes the fee acknowledgement for a transform command, such as
+// <fee:create>, if extData holds a "fee:fee" amount. The currency is read
+// from "fee:currency". The namespace is negotiated from greeting, falling
+// back to fee-1.0. It returns false if there is no fee to acknowledge.
+// https://tools.ietf.org/html/rfc8748#section-5.2
+func encodeFee(buf *bytes.Buffer, greeting *Greeting, cmd string, extData map[string]string) bool {
+	fee, ok := extData["fee:fee"]
+	if !ok {
+		return false
+	}
+	urn := greeting.feeURN()
+	if urn == "" {
+		urn = ExtFee10
+	}
+	buf.WriteString(`<fee:` + cmd + ` xmlns:fee="`)
+	buf.WriteString(urn)
+	buf.WriteString(`">`)
+	if currency, ok := extData["fee:currency"]; ok {
+		buf.WriteString(`<fee:currency>`)
+		xml.EscapeText(buf, []byte(currency))
+		buf.WriteString(`</fee:currency>`)
+	}
+	buf.WriteString(`<fee:fee>`)
+	xml.EscapeText(buf, []byte(fee))
+	buf.WriteString(`</fee:fee>`)
+	buf.WriteString(`</fee:` + cmd + `>`)
+	return true
+}
+
+// FeeData represents the fees charged by a transform command, returned in
+// the fee extension of its response.
+// https://tools.ietf.org/html/rfc8748#section-5.2
+type FeeData struct {
+	Currency    string // <fee:currency>
+	Period      int    // <fee:period>
+	Unit        string // unit attribute of <fee:period>
+	Fees        []Fee  // <fee:fee>
+	Credits     []Fee  // <fee:credit>
+	Balance     string // <fee:balance>: account balance after the command
+	CreditLimit string // <fee:creditLimit>
+}
+
+func init() {
+	datas := []struct {
+		name, cmd string
+		fee       func(*Response) *FeeData
+	}{
+		{"creData", "create", func(res *Response) *FeeData { return &res.DomainCreateResponse.Fee }},
+		{"renData", "renew", func(res *Response) *FeeData { return &res.DomainRenewResponse.Fee }},
+		{"trnData", "transfer", func(res *Response) *FeeData { return &res.DomainTransferResponse.Fee }},
+		{"upData", "update", func(res *Response) *FeeData { return &res.DomainUpdateResponse.Fee }},
+	}
+	for _, urn := range feeURNs {
+		for _, d := range datas {
+			path := "epp > response > extension > " + urn + " " + d.name
+			feeData, cmd := d.fee, d.cmd
+			scanResponse.MustHandleCharData(path+">currency", func(c *xx.Context) error {
+				feeData(c.Value.(*Response)).Currency = string(c.CharData)
+				return nil
+			})
+			scanResponse.MustHandleCharData(path+">period", func(c *xx.Context) error {
+				fd := feeData(c.Value.(*Response))
+				fd.Unit = c.Attr("", "unit")
+				var err error
+				fd.Period, err = strconv.Atoi(string(c.CharData))
+				return err
+			})
+			scanResponse.MustHandleCharData(path+">fee", func(c *xx.Context) error {
+				fd := feeData(c.Value.(*Response))
+				fd.Fees = append(fd.Fees, scanFee(c, cmd, fd.Currency))
+				return nil
+			})
+			scanResponse.MustHandleCharData(path+">credit", func(c *xx.Context) error {
+				fd := feeData(c.Value.(*Response))
+				fd.Credits = append(fd.Credits, scanFee(c, cmd, fd.Currency))
+				return nil
+			})
+			scanResponse.MustHandleCharData(path+">balance", func(c *xx.Context) error {
+				feeData(c.Value.(*Response)).Balance = string(c.CharData)
+				return nil
+			})
+			scanResponse.MustHandleCharData(path+">creditLimit", func(c *xx.Context) error {
+				feeData(c.Value.(*Response)).CreditLimit = string(c.CharData)
+				return nil
+			})
+		}
+	}
+}
+
+// scanFee reads a <fee:fee> or <fee:credit> element.
+func scanFee(c *xx.Context, cmd, currency string) Fee {
+	return Fee{
+		Name:        cmd,
+		Amount:      string(c.CharData),
+		Currency:    currency,
+		Description: c.Attr("", "description"),
+		Refundable:  c.AttrBool("", "refundable"),
+		GracePeriod: c.Attr("", "grace-period"),
+	}
+}
@@ -0,0 +1,176 @@
+package epp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeFeeNegotiatedVersion(t *testing.T) {
+	greeting := &Greeting{Extensions: []string{ExtFee11, ExtFee21}}
+	extData := map[string]string{"fee:fee": "50.00", "fee:currency": "USD"}
+
+	x, err := encodeDomainRenew(greeting, "example.com", time.Date(2025, 4, 3, 0, 0, 0, 0, time.UTC), 1, "y", extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><renew><domain:renew xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:curExpDate>2025-04-03</domain:curExpDate><domain:period unit="y">1</domain:period></domain:renew></renew><extension><fee:renew xmlns:fee="urn:ietf:params:xml:ns:fee-0.21"><fee:currency>USD</fee:currency><fee:fee>50.00</fee:fee></fee:renew></extension></command></epp>`)
+
+	x, err = encodeDomainCreate(greeting, "example.com", 1, "y", "", "", nil, nil, extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="y">1</domain:period></domain:create></create><extension><fee:create xmlns:fee="urn:ietf:params:xml:ns:fee-0.21"><fee:currency>USD</fee:currency><fee:fee>50.00</fee:fee></fee:create></extension></command></epp>`)
+
+	x, err = encodeDomainTransfer(greeting, "request", "example.com", 0, "y", "", extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:transfer></transfer><extension><fee:transfer xmlns:fee="urn:ietf:params:xml:ns:fee-0.21"><fee:currency>USD</fee:currency><fee:fee>50.00</fee:fee></fee:transfer></extension></command></epp>`)
+}
+
+func TestEncodeFeeRestoreAndUpdate(t *testing.T) {
+	greeting := &Greeting{Extensions: []string{ExtFee10}}
+	extData := map[string]string{"fee:fee": "40.00"}
+
+	x, err := encodeDomainRestore(greeting, "example.com", extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:update></update><extension><rgp:update xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0"><rgp:restore op="request"/></rgp:update><fee:update xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0"><fee:fee>40.00</fee:fee></fee:update></extension></command></epp>`)
+
+	du := &DomainUpdate{
+		Domain:     "example.com",
+		Chg:        DomainChange{Registrant: "reg2"},
+		Extensions: extData,
+	}
+	x, err = du.encode(greeting)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:chg><domain:registrant>reg2</domain:registrant></domain:chg></domain:update></update><extension><fee:update xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0"><fee:fee>40.00</fee:fee></fee:update></extension></command></epp>`)
+}
+
+func TestEncodeFeeEscaped(t *testing.T) {
+	extData := map[string]string{"fee:fee": "1<2", "fee:currency": "A&B"}
+	x, err := encodeDomainRestore(&Greeting{Extensions: []string{ExtFee10}}, "example.com", extData)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:update></update><extension><rgp:update xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0"><rgp:restore op="request"/></rgp:update><fee:update xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0"><fee:currency>A&amp;B</fee:currency><fee:fee>1&lt;2</fee:fee></fee:update></extension></command></epp>`)
+}
+
+func TestDomainUpdateFee(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		return `<epp><response><result code="1000"><msg>Command completed successfully</msg></result><extension><fee:upData xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0"><fee:currency>USD</fee:currency><fee:fee>5.00</fee:fee><fee:balance>95.00</fee:balance></fee:upData></extension></response></epp>`
+	})
+
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+	var res *DomainUpdateResponse
+	err = p.Do(context.Background(), func(c *Conn) error {
+		var err error
+		res, err = c.DomainUpdate(&DomainUpdate{Domain: "example.com", Chg: DomainChange{Registrant: "reg2"}})
+		return err
+	})
+	st.Assert(t, err, nil)
+	st.Expect(t, res.Fee.Currency, "USD")
+	st.Expect(t, res.Fee.Fees, []Fee{{Name: "update", Amount: "5.00", Currency: "USD"}})
+	st.Expect(t, res.Fee.Balance, "95.00")
+}
+
+func TestScanFeeCreateResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <domain:creData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:crDate>1999-04-03T22:00:00.0Z</domain:crDate>
+        <domain:exDate>2001-04-03T22:00:00.0Z</domain:exDate>
+      </domain:creData>
+    </resData>
+    <extension>
+      <fee:creData xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0">
+        <fee:currency>USD</fee:currency>
+        <fee:fee description="Registration Fee" refundable="1" grace-period="P5D">5.00</fee:fee>
+        <fee:balance>-5.00</fee:balance>
+        <fee:creditLimit>1000.00</fee:creditLimit>
+      </fee:creData>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainCreateResponse.Fee, FeeData{
+		Currency: "USD",
+		Fees: []Fee{{
+			Name:        "create",
+			Amount:      "5.00",
+			Currency:    "USD",
+			Description: "Registration Fee",
+			Refundable:  true,
+			GracePeriod: "P5D",
+		}},
+		Balance:     "-5.00",
+		CreditLimit: "1000.00",
+	})
+}
+
+func TestScanFeeRenewAndTransferResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <extension>
+      <fee:renData xmlns:fee="urn:ietf:params:xml:ns:fee-0.11">
+        <fee:currency>EUR</fee:currency>
+        <fee:period unit="y">2</fee:period>
+        <fee:fee>10.00</fee:fee>
+        <fee:balance>990.00</fee:balance>
+      </fee:renData>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainRenewResponse.Fee, FeeData{
+		Currency: "EUR",
+		Period:   2,
+		Unit:     "y",
+		Fees:     []Fee{{Name: "renew", Amount: "10.00", Currency: "EUR"}},
+		Balance:  "990.00",
+	})
+
+	x = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1001">
+      <msg>Command completed successfully; action pending</msg>
+    </result>
+    <extension>
+      <fee:trnData xmlns:fee="urn:ietf:params:xml:ns:epp:fee-1.0">
+        <fee:currency>USD</fee:currency>
+        <fee:fee>5.00</fee:fee>
+        <fee:credit description="Refund">-2.00</fee:credit>
+      </fee:trnData>
+    </extension>
+  </response>
+</epp>`
+
+	err = IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainTransferResponse.Fee, FeeData{
+		Currency: "USD",
+		Fees:     []Fee{{Name: "transfer", Amount: "5.00", Currency: "USD"}},
+		Credits:  []Fee{{Name: "transfer", Amount: "-2.00", Currency: "USD", Description: "Refund"}},
+	})
+}
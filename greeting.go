@@ -1,18 +1,21 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 
 	"github.com/nbio/xx"
 )
 
 // Hello sends a <hello> command to request a <greeting> from the EPP server.
+// The greeting carries no transaction identifiers, so only an error is returned.
 func (c *Conn) Hello() error {
-	err := c.writeRequest(xmlHello)
-	if err != nil {
-		return err
-	}
-	_, err = c.readGreeting()
+	return c.HelloContext(context.Background())
+}
+
+// HelloContext is like Hello, but honors cancellation and deadlines from ctx.
+func (c *Conn) HelloContext(ctx context.Context) error {
+	_, err := c.request(ctx, xmlHello)
 	return err
 }
 
@@ -80,6 +83,11 @@ const (
 	ExtNeulevel   = "urn:ietf:params:xml:ns:neulevel"
 	ExtNeulevel10 = "urn:ietf:params:xml:ns:neulevel-1.0"
 	ExtFrnic20    = "http://www.afnic.fr/xml/epp/frnic-2.0"
+
+	ExtAllocationToken = "urn:ietf:params:xml:ns:allocationToken-1.0"
+	ExtLoginSec        = "urn:ietf:params:xml:ns:epp:loginSec-1.0"
+	ExtChangePoll      = "urn:ietf:params:xml:ns:changePoll-1.0"
+	ExtLowBalance      = "http://www.verisign.com/epp/lowbalance-poll-1.0"
 )
 
 // ExtURNNames maps short extension names to their full URN.
@@ -102,11 +110,17 @@ var ExtURNNames = map[string]string{
 	"neulevel":         ExtNeulevel,
 	"neulevel-1.0":     ExtNeulevel10,
 	"frnic-2.0":        ExtFrnic20,
+
+	"allocationToken-1.0": ExtAllocationToken,
+	"loginSec-1.0":        ExtLoginSec,
+	"changePoll-1.0":      ExtChangePoll,
+	"lowbalance-poll-1.0": ExtLowBalance,
 }
 
+// readGreeting reads the <greeting> sent by the server when a connection is opened.
 // TODO: check if res.Greeting is not empty.
-func (c *Conn) readGreeting() (Greeting, error) {
-	res, err := c.readResponse()
+func (c *Conn) readGreeting(ctx context.Context) (Greeting, error) {
+	res, err := c.request(ctx, nil)
 	if err != nil {
 		return Greeting{}, err
 	}
@@ -2,38 +2,83 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"fmt"
 	"time"
 
 	"github.com/nbio/xx"
 )
 
-// DomainInfo retrieves info for a domain.
+// DomainInfo retrieves info for a domain, without host information.
 // https://tools.ietf.org/html/rfc5731#section-3.1.2
 func (c *Conn) DomainInfo(domain string, extData map[string]string) (*DomainInfoResponse, error) {
-	x, err := encodeDomainInfo(&c.Greeting, domain, extData)
-	if err != nil {
-		return nil, err
-	}
-	err = c.writeRequest(x)
+	return c.DomainInfoContext(context.Background(), domain, extData)
+}
+
+// DomainInfoContext is like DomainInfo, but honors cancellation and deadlines from ctx.
+func (c *Conn) DomainInfoContext(ctx context.Context, domain string, extData map[string]string) (*DomainInfoResponse, error) {
+	return c.DomainInfoAuthContext(ctx, domain, HostsNone, "", extData)
+}
+
+// DomainInfoAuth retrieves info for a domain. hosts selects the host
+// information returned (HostsAll, HostsDelegated, HostsSubordinate or
+// HostsNone); an empty hosts uses the server default (all).
+// auth is the domain authInfo password, needed to retrieve full data
+// for a domain sponsored by another registrar.
+// https://tools.ietf.org/html/rfc5731#section-3.1.2
+func (c *Conn) DomainInfoAuth(domain, hosts, auth string, extData map[string]string) (*DomainInfoResponse, error) {
+	return c.DomainInfoAuthContext(context.Background(), domain, hosts, auth, extData)
+}
+
+// DomainInfoAuthContext is like DomainInfoAuth, but honors cancellation and deadlines from ctx.
+func (c *Conn) DomainInfoAuthContext(ctx context.Context, domain, hosts, auth string, extData map[string]string) (*DomainInfoResponse, error) {
+	x, err := encodeDomainInfo(&c.Greeting, domain, hosts, auth, extData)
 	if err != nil {
 		return nil, err
 	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
 	return &res.DomainInfoResponse, nil
 }
 
-func encodeDomainInfo(greeting *Greeting, domain string, extData map[string]string) ([]byte, error) {
+// Values for the hosts attribute of a domain info command.
+// https://tools.ietf.org/html/rfc5731#section-3.1.2
+const (
+	HostsAll         = "all"  // delegated and subordinate hosts
+	HostsDelegated   = "del"  // delegated hosts only
+	HostsSubordinate = "sub"  // subordinate hosts only
+	HostsNone        = "none" // no host information
+)
+
+func encodeDomainInfo(greeting *Greeting, domain, hosts, auth string, extData map[string]string) ([]byte, error) {
+	switch hosts {
+	case "", HostsAll, HostsDelegated, HostsSubordinate, HostsNone:
+	default:
+		return nil, fmt.Errorf("epp: invalid hosts value %q", hosts)
+	}
 	buf := bytes.NewBufferString(xmlCommandPrefix)
-	buf.WriteString(`<info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="none">`)
+	buf.WriteString(`<info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name`)
+	if hosts != "" {
+		buf.WriteString(` hosts="`)
+		buf.WriteString(hosts)
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(`>`)
 	xml.EscapeText(buf, []byte(domain))
-	buf.WriteString(`</domain:name></domain:info></info>`)
+	buf.WriteString(`</domain:name>`)
+	if auth != "" {
+		buf.WriteString(`<domain:authInfo><domain:pw>`)
+		xml.EscapeText(buf, []byte(auth))
+		buf.WriteString(`</domain:pw></domain:authInfo>`)
+	}
+	buf.WriteString(`</domain:info></info>`)
 
 	supportsNamestore := extData["namestoreExt:subProduct"] != "" && greeting.SupportsExtension(ExtNamestore)
-	hasExtension := supportsNamestore
+	tokenInfo := extData[AllocationTokenInfoKey] != ""
+	hasExtension := supportsNamestore || tokenInfo
 
 	if hasExtension {
 		buf.WriteString(`<extension>`)
@@ -47,6 +92,9 @@ func encodeDomainInfo(greeting *Greeting, domain string, extData map[string]stri
 			buf.WriteString(`</namestoreExt:subProduct>`)
 			buf.WriteString(`</namestoreExt:namestoreExt>`)
 		}
+		if tokenInfo {
+			encodeAllocationTokenInfo(buf)
+		}
 		buf.WriteString(`</extension>`)
 	}
 
@@ -58,15 +106,54 @@ func encodeDomainInfo(greeting *Greeting, domain string, extData map[string]stri
 // DomainInfoResponse represents an EPP response for a domain info request.
 // https://tools.ietf.org/html/rfc5731#section-3.1.2
 type DomainInfoResponse struct {
-	Domain string    // <domain:name>
-	ID     string    // <domain:roid>
-	ClID   string    // <domain:clID>
-	UpID   string    // <domain:upID>
-	CrDate time.Time // <domain:crDate>
-	ExDate time.Time // <domain:exDate>
-	UpDate time.Time // <domain:upDate>
-	TrDate time.Time // <domain:trDate>
-	Status []string  // <domain:status>
+	Domain          string          // <domain:name>
+	ID              string          // <domain:roid>
+	Status          []string        // <domain:status s="...">
+	StatusDetails   []ObjectStatus  // <domain:status>, with lang and text
+	Registrant      string          // <domain:registrant>
+	Contacts        []DomainContact // <domain:contact>
+	Nameservers     []string        // <domain:ns><domain:hostObj>
+	NameserverAttrs []HostAttr      // <domain:ns><domain:hostAttr>
+	Hosts           []string        // <domain:host>, subordinate hosts
+	ClID            string          // <domain:clID>
+	CrID            string          // <domain:crID>
+	UpID            string          // <domain:upID>
+	CrDate          time.Time       // <domain:crDate>
+	ExDate          time.Time       // <domain:exDate>
+	UpDate          time.Time       // <domain:upDate>
+	TrDate          time.Time       // <domain:trDate>
+	AuthInfo        string          // <domain:authInfo><domain:pw>
+	SecDNS          SecDNSData      // <secDNS:infData>
+	RGPStatus       []string        // <rgp:infData><rgp:rgpStatus s="...">
+	Launch          LaunchInfo      // <launch:infData>
+	AllocationToken string          // <allocationToken:allocationToken>
+	TransactionID
+}
+
+// ObjectStatus represents a <status> element of an EPP object,
+// with its optional human-readable text.
+type ObjectStatus struct {
+	Status string // s attribute
+	Lang   string // lang attribute
+	Text   string
+}
+
+// DomainContact represents a <domain:contact> element.
+type DomainContact struct {
+	Type string // admin, billing or tech
+	ID   string
+}
+
+// HostAttr represents a host in a <domain:hostAttr> element.
+type HostAttr struct {
+	Name  string     // <domain:hostName>
+	Addrs []HostAddr // <domain:hostAddr>
+}
+
+// HostAddr represents an IP address of a host.
+type HostAddr struct {
+	IP      string // v4 or v6
+	Address string
 }
 
 func init() {
@@ -82,11 +169,52 @@ func init() {
 		dir.ID = string(c.CharData)
 		return nil
 	})
+	scanResponse.MustHandleCharData(path+">registrant", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Registrant = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">contact", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Contacts = append(dir.Contacts, DomainContact{Type: c.Attr("", "type"), ID: string(c.CharData)})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostObj", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Nameservers = append(dir.Nameservers, string(c.CharData))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">ns>hostAttr", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.NameserverAttrs = append(dir.NameserverAttrs, HostAttr{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostAttr>hostName", func(c *xx.Context) error {
+		attrs := c.Value.(*Response).DomainInfoResponse.NameserverAttrs
+		attrs[len(attrs)-1].Name = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">ns>hostAttr>hostAddr", func(c *xx.Context) error {
+		attrs := c.Value.(*Response).DomainInfoResponse.NameserverAttrs
+		attr := &attrs[len(attrs)-1]
+		attr.Addrs = append(attr.Addrs, HostAddr{IP: hostAddrIP(c), Address: string(c.CharData)})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">host", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.Hosts = append(dir.Hosts, string(c.CharData))
+		return nil
+	})
 	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
 		dir := &c.Value.(*Response).DomainInfoResponse
 		dir.ClID = string(c.CharData)
 		return nil
 	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.CrID = string(c.CharData)
+		return nil
+	})
 	scanResponse.MustHandleCharData(path+">upID", func(c *xx.Context) error {
 		dir := &c.Value.(*Response).DomainInfoResponse
 		dir.UpID = string(c.CharData)
@@ -119,10 +247,39 @@ func init() {
 	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
 		dir := &c.Value.(*Response).DomainInfoResponse
 		dir.Status = append(dir.Status, c.Attr("", "s"))
+		dir.StatusDetails = append(dir.StatusDetails, ObjectStatus{Status: c.Attr("", "s"), Lang: c.Attr("", "lang")})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">status", func(c *xx.Context) error {
+		details := c.Value.(*Response).DomainInfoResponse.StatusDetails
+		details[len(details)-1].Text = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">authInfo>pw", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.AuthInfo = string(c.CharData)
+		return nil
+	})
+
+	// RGP status in the response extension
+	// https://tools.ietf.org/html/rfc3915#section-4.1.2
+	path = "epp > response > extension > " + ExtRGP + " infData"
+	scanResponse.MustHandleStartElement(path+">rgpStatus", func(c *xx.Context) error {
+		dir := &c.Value.(*Response).DomainInfoResponse
+		dir.RGPStatus = append(dir.RGPStatus, c.Attr("", "s"))
 		return nil
 	})
 }
 
+// hostAddrIP returns the ip attribute of a <hostAddr> element,
+// which defaults to v4.
+func hostAddrIP(c *xx.Context) string {
+	if ip := c.Attr("", "ip"); ip != "" {
+		return ip
+	}
+	return "v4"
+}
+
 //lint:ignore U1000 keeping around for reference
 func encodeVerisignDomainInfo(buf *bytes.Buffer, domain string) error {
 	buf.Reset()
@@ -144,15 +301,16 @@ func encodeVerisignDomainInfo(buf *bytes.Buffer, domain string) error {
 // ContactInfo retrieves info for a contact.
 // https://tools.ietf.org/html/rfc5733#section-3.1.2
 func (c *Conn) ContactInfo(id string, auth string, extData map[string]string) (*ContactInfoResponse, error) {
+	return c.ContactInfoContext(context.Background(), id, auth, extData)
+}
+
+// ContactInfoContext is like ContactInfo, but honors cancellation and deadlines from ctx.
+func (c *Conn) ContactInfoContext(ctx context.Context, id string, auth string, extData map[string]string) (*ContactInfoResponse, error) {
 	x, err := encodeContactInfo(&c.Greeting, id, auth, extData)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -177,20 +335,27 @@ func encodeContactInfo(greeting *Greeting, id string, auth string, extData map[s
 }
 
 // ContactInfoResponse represents an EPP response for a contact info request.
+// https://tools.ietf.org/html/rfc5733#section-3.1.2
 type ContactInfoResponse struct {
-	ID     string       // <contact:id>
-	ROID   string       // <contact:roid>
-	Status []string     // <contact:status>
-	Postal []PostalInfo // <contact:postalInfo>
-	Voice  string       // <contact:voice>
-	Fax    string       // <contact:fax>
-	Email  string       // <contact:email>
-	ClID   string       // <contact:clID>
-	CrID   string       // <contact:crID>
-	UpID   string       // <contact:upID>
-	CrDate time.Time    // <contact:crDate>
-	UpDate time.Time    // <contact:upDate>
-	TrDate time.Time    // <contact:trDate>
+	ID            string         // <contact:id>
+	ROID          string         // <contact:roid>
+	Status        []string       // <contact:status>
+	StatusDetails []ObjectStatus // <contact:status>, with lang and text
+	Postal        []PostalInfo   // <contact:postalInfo>, int and/or loc
+	Voice         string         // <contact:voice>
+	VoiceExt      string         // <contact:voice x="...">
+	Fax           string         // <contact:fax>
+	FaxExt        string         // <contact:fax x="...">
+	Email         string         // <contact:email>
+	ClID          string         // <contact:clID>
+	CrID          string         // <contact:crID>
+	UpID          string         // <contact:upID>
+	CrDate        time.Time      // <contact:crDate>
+	UpDate        time.Time      // <contact:upDate>
+	TrDate        time.Time      // <contact:trDate>
+	AuthInfo      string         // <contact:authInfo><contact:pw>
+	Disclose      *Disclose      // <contact:disclose>
+	TransactionID
 }
 
 func init() {
@@ -208,6 +373,72 @@ func init() {
 	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
 		cir := &c.Value.(*Response).ContactInfoResponse
 		cir.Status = append(cir.Status, c.Attr("", "s"))
+		cir.StatusDetails = append(cir.StatusDetails, ObjectStatus{Status: c.Attr("", "s"), Lang: c.Attr("", "lang")})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">status", func(c *xx.Context) error {
+		details := c.Value.(*Response).ContactInfoResponse.StatusDetails
+		details[len(details)-1].Text = string(c.CharData)
+		return nil
+	})
+
+	// Postal info, either int or loc
+	postal := func(c *xx.Context) *PostalInfo {
+		pis := c.Value.(*Response).ContactInfoResponse.Postal
+		return &pis[len(pis)-1]
+	}
+	scanResponse.MustHandleStartElement(path+">postalInfo", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.Postal = append(cir.Postal, PostalInfo{Type: c.Attr("", "type")})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>name", func(c *xx.Context) error {
+		postal(c).Name = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>org", func(c *xx.Context) error {
+		postal(c).Org = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>addr>street", func(c *xx.Context) error {
+		pi := postal(c)
+		switch {
+		case pi.Street == "":
+			pi.Street = string(c.CharData)
+		case pi.Street2 == "":
+			pi.Street2 = string(c.CharData)
+		default:
+			pi.Street3 = string(c.CharData)
+		}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>addr>city", func(c *xx.Context) error {
+		postal(c).City = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>addr>sp", func(c *xx.Context) error {
+		postal(c).SP = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>addr>pc", func(c *xx.Context) error {
+		postal(c).PC = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">postalInfo>addr>cc", func(c *xx.Context) error {
+		postal(c).CC = string(c.CharData)
+		return nil
+	})
+
+	scanResponse.MustHandleCharData(path+">voice", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.Voice = string(c.CharData)
+		cir.VoiceExt = c.Attr("", "x")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">fax", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.Fax = string(c.CharData)
+		cir.FaxExt = c.Attr("", "x")
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+">email", func(c *xx.Context) error {
@@ -215,13 +446,194 @@ func init() {
 		cir.Email = string(c.CharData)
 		return nil
 	})
+	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.ClID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.CrID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">upID", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.UpID = string(c.CharData)
+		return nil
+	})
 	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
 		cir := &c.Value.(*Response).ContactInfoResponse
 		var err error
 		cir.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
 		return err
 	})
-	// Add other fields as needed, keeping it minimal for now based on CLI usage
+	scanResponse.MustHandleCharData(path+">upDate", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		var err error
+		cir.UpDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">trDate", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		var err error
+		cir.TrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">authInfo>pw", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.AuthInfo = string(c.CharData)
+		return nil
+	})
+
+	// Disclosure preferences
+	disclose := func(c *xx.Context) *Disclose {
+		return c.Value.(*Response).ContactInfoResponse.Disclose
+	}
+	scanResponse.MustHandleStartElement(path+">disclose", func(c *xx.Context) error {
+		cir := &c.Value.(*Response).ContactInfoResponse
+		cir.Disclose = &Disclose{Flag: c.AttrBool("", "flag")}
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>name", func(c *xx.Context) error {
+		d := disclose(c)
+		d.Name = append(d.Name, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>org", func(c *xx.Context) error {
+		d := disclose(c)
+		d.Org = append(d.Org, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>addr", func(c *xx.Context) error {
+		d := disclose(c)
+		d.Addr = append(d.Addr, c.Attr("", "type"))
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>voice", func(c *xx.Context) error {
+		disclose(c).Voice = true
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>fax", func(c *xx.Context) error {
+		disclose(c).Fax = true
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">disclose>email", func(c *xx.Context) error {
+		disclose(c).Email = true
+		return nil
+	})
+}
+
+// HostInfo retrieves info for a host.
+// https://tools.ietf.org/html/rfc5732#section-3.1.2
+func (c *Conn) HostInfo(host string) (*HostInfoResponse, error) {
+	return c.HostInfoContext(context.Background(), host)
+}
+
+// HostInfoContext is like HostInfo, but honors cancellation and deadlines from ctx.
+func (c *Conn) HostInfoContext(ctx context.Context, host string) (*HostInfoResponse, error) {
+	x, err := encodeHostInfo(host)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.HostInfoResponse, nil
+}
+
+func encodeHostInfo(host string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<info><host:info xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>`)
+	xml.EscapeText(buf, []byte(host))
+	buf.WriteString(`</host:name></host:info></info>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// HostInfoResponse represents an EPP response for a host info request.
+// https://tools.ietf.org/html/rfc5732#section-3.1.2
+type HostInfoResponse struct {
+	Host          string         // <host:name>
+	ID            string         // <host:roid>
+	Status        []string       // <host:status s="...">
+	StatusDetails []ObjectStatus // <host:status>, with lang and text
+	IPv4          []string       // <host:addr ip="v4">
+	IPv6          []string       // <host:addr ip="v6">
+	ClID          string         // <host:clID>
+	CrID          string         // <host:crID>
+	UpID          string         // <host:upID>
+	CrDate        time.Time      // <host:crDate>
+	UpDate        time.Time      // <host:upDate>
+	TrDate        time.Time      // <host:trDate>
+	TransactionID
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjHost + " infData"
+	scanResponse.MustHandleCharData(path+">name", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.Host = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">roid", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.ID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.Status = append(hir.Status, c.Attr("", "s"))
+		hir.StatusDetails = append(hir.StatusDetails, ObjectStatus{Status: c.Attr("", "s"), Lang: c.Attr("", "lang")})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">status", func(c *xx.Context) error {
+		details := c.Value.(*Response).HostInfoResponse.StatusDetails
+		details[len(details)-1].Text = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">addr", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		if hostAddrIP(c) == "v6" {
+			hir.IPv6 = append(hir.IPv6, string(c.CharData))
+		} else {
+			hir.IPv4 = append(hir.IPv4, string(c.CharData))
+		}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">clID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.ClID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.CrID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">upID", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		hir.UpID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">crDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		var err error
+		hir.CrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">upDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		var err error
+		hir.UpDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">trDate", func(c *xx.Context) error {
+		hir := &c.Value.(*Response).HostInfoResponse
+		var err error
+		hir.TrDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
 }
 
 /*
@@ -3,12 +3,13 @@ package epp
 import (
 	"encoding/xml"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
 
 func TestEncodeDomainInfo(t *testing.T) {
-	x, err := encodeDomainInfo(&Greeting{}, "example.com", nil)
+	x, err := encodeDomainInfo(&Greeting{}, "example.com", HostsNone, "", nil)
 	st.Expect(t, err, nil)
 	expected := `<?xml version="1.0" encoding="UTF-8"?>
 <epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="none">example.com</domain:name></domain:info></info></command></epp>`
@@ -26,7 +27,7 @@ func TestEncodeDomainInfoWithNamestore(t *testing.T) {
 	extData := map[string]string{
 		"namestoreExt:subProduct": "COM",
 	}
-	x, err := encodeDomainInfo(greeting, "example.com", extData)
+	x, err := encodeDomainInfo(greeting, "example.com", HostsNone, "", extData)
 	st.Expect(t, err, nil)
 	expected := `<?xml version="1.0" encoding="UTF-8"?>
 <epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="none">example.com</domain:name></domain:info></info><extension><namestoreExt:namestoreExt xmlns:namestoreExt="http://www.verisign-grs.com/epp/namestoreExt-1.1"><namestoreExt:subProduct>COM</namestoreExt:subProduct></namestoreExt:namestoreExt></extension></command></epp>`
@@ -48,3 +49,287 @@ func TestEncodeContactInfo(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestEncodeDomainInfoAuth(t *testing.T) {
+	x, err := encodeDomainInfo(&Greeting{}, "example.com", HostsAll, "2fooBAR", nil)
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="all">example.com</domain:name><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:info></info></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	x, err = encodeDomainInfo(&Greeting{}, "example.com", "", "", nil)
+	st.Expect(t, err, nil)
+	expected = `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:info></info></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	_, err = encodeDomainInfo(&Greeting{}, "example.com", "some", "", nil)
+	st.Reject(t, err, nil)
+}
+
+func TestScanDomainInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:roid>EXAMPLE1-REP</domain:roid>
+        <domain:status s="ok"/>
+        <domain:status s="clientHold" lang="en">Payment overdue.</domain:status>
+        <domain:registrant>jd1234</domain:registrant>
+        <domain:contact type="admin">sh8013</domain:contact>
+        <domain:contact type="tech">sh8014</domain:contact>
+        <domain:ns>
+          <domain:hostObj>ns1.example.com</domain:hostObj>
+          <domain:hostObj>ns1.example.net</domain:hostObj>
+          <domain:hostAttr>
+            <domain:hostName>ns2.example.com</domain:hostName>
+            <domain:hostAddr ip="v4">192.0.2.2</domain:hostAddr>
+            <domain:hostAddr ip="v6">1080:0:0:0:8:800:200C:417A</domain:hostAddr>
+          </domain:hostAttr>
+        </domain:ns>
+        <domain:host>ns1.example.com</domain:host>
+        <domain:host>ns2.example.com</domain:host>
+        <domain:clID>ClientX</domain:clID>
+        <domain:crID>ClientY</domain:crID>
+        <domain:crDate>1999-04-03T22:00:00.0Z</domain:crDate>
+        <domain:upID>ClientX</domain:upID>
+        <domain:upDate>1999-12-03T09:00:00.0Z</domain:upDate>
+        <domain:exDate>2005-04-03T22:00:00.0Z</domain:exDate>
+        <domain:trDate>2000-04-08T09:00:00.0Z</domain:trDate>
+        <domain:authInfo>
+          <domain:pw>2fooBAR</domain:pw>
+        </domain:authInfo>
+      </domain:infData>
+    </resData>
+    <extension>
+      <secDNS:infData xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1">
+        <secDNS:maxSigLife>604800</secDNS:maxSigLife>
+        <secDNS:dsData>
+          <secDNS:keyTag>12345</secDNS:keyTag>
+          <secDNS:alg>3</secDNS:alg>
+          <secDNS:digestType>1</secDNS:digestType>
+          <secDNS:digest>49FD46E6C4B45C55D4AC</secDNS:digest>
+          <secDNS:keyData>
+            <secDNS:flags>257</secDNS:flags>
+            <secDNS:protocol>3</secDNS:protocol>
+            <secDNS:alg>1</secDNS:alg>
+            <secDNS:pubKey>AQPJ////4Q==</secDNS:pubKey>
+          </secDNS:keyData>
+        </secDNS:dsData>
+      </secDNS:infData>
+      <rgp:infData xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0">
+        <rgp:rgpStatus s="redemptionPeriod"/>
+      </rgp:infData>
+    </extension>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	d := decoder(x)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	dir := &res.DomainInfoResponse
+	st.Expect(t, dir.Domain, "example.com")
+	st.Expect(t, dir.ID, "EXAMPLE1-REP")
+	st.Expect(t, dir.Status, []string{"ok", "clientHold"})
+	st.Expect(t, dir.StatusDetails, []ObjectStatus{{Status: "ok"}, {Status: "clientHold", Lang: "en", Text: "Payment overdue."}})
+	st.Expect(t, dir.Registrant, "jd1234")
+	st.Expect(t, dir.Contacts, []DomainContact{{Type: "admin", ID: "sh8013"}, {Type: "tech", ID: "sh8014"}})
+	st.Expect(t, dir.Nameservers, []string{"ns1.example.com", "ns1.example.net"})
+	st.Expect(t, dir.NameserverAttrs, []HostAttr{{
+		Name: "ns2.example.com",
+		Addrs: []HostAddr{
+			{IP: "v4", Address: "192.0.2.2"},
+			{IP: "v6", Address: "1080:0:0:0:8:800:200C:417A"},
+		},
+	}})
+	st.Expect(t, dir.Hosts, []string{"ns1.example.com", "ns2.example.com"})
+	st.Expect(t, dir.ClID, "ClientX")
+	st.Expect(t, dir.CrID, "ClientY")
+	st.Expect(t, dir.UpID, "ClientX")
+	st.Expect(t, dir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.ExDate, time.Date(2005, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, dir.AuthInfo, "2fooBAR")
+	st.Expect(t, dir.SecDNS, SecDNSData{
+		MaxSigLife: 604800,
+		DS: []DSData{{
+			KeyTag:     12345,
+			Alg:        3,
+			DigestType: 1,
+			Digest:     "49FD46E6C4B45C55D4AC",
+			KeyData:    &KeyData{Flags: 257, Protocol: 3, Alg: 1, PubKey: "AQPJ////4Q=="},
+		}},
+	})
+	st.Expect(t, dir.RGPStatus, []string{"redemptionPeriod"})
+}
+
+func TestEncodeHostInfo(t *testing.T) {
+	x, err := encodeHostInfo("ns1.example.com")
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><host:info xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name></host:info></info></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestScanHostInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <host:infData xmlns:host="urn:ietf:params:xml:ns:host-1.0">
+        <host:name>ns1.example.com</host:name>
+        <host:roid>NS1_EXAMPLE1-REP</host:roid>
+        <host:status s="linked"/>
+        <host:status s="clientUpdateProhibited"/>
+        <host:addr ip="v4">192.0.2.2</host:addr>
+        <host:addr ip="v4">192.0.2.29</host:addr>
+        <host:addr ip="v6">1080:0:0:0:8:800:200C:417A</host:addr>
+        <host:clID>ClientY</host:clID>
+        <host:crID>ClientX</host:crID>
+        <host:crDate>1999-04-03T22:00:00.0Z</host:crDate>
+        <host:upID>ClientX</host:upID>
+        <host:upDate>1999-12-03T09:00:00.0Z</host:upDate>
+        <host:trDate>2000-04-08T09:00:00.0Z</host:trDate>
+      </host:infData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	hir := &res.HostInfoResponse
+	st.Expect(t, hir.Host, "ns1.example.com")
+	st.Expect(t, hir.ID, "NS1_EXAMPLE1-REP")
+	st.Expect(t, hir.Status, []string{"linked", "clientUpdateProhibited"})
+	st.Expect(t, hir.IPv4, []string{"192.0.2.2", "192.0.2.29"})
+	st.Expect(t, hir.IPv6, []string{"1080:0:0:0:8:800:200C:417A"})
+	st.Expect(t, hir.ClID, "ClientY")
+	st.Expect(t, hir.CrID, "ClientX")
+	st.Expect(t, hir.UpID, "ClientX")
+	st.Expect(t, hir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, hir.UpDate, time.Date(1999, 12, 3, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, hir.TrDate, time.Date(2000, 4, 8, 9, 0, 0, 0, time.UTC))
+}
+
+func TestScanContactInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <contact:infData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+        <contact:id>sh8013</contact:id>
+        <contact:roid>SH8013-REP</contact:roid>
+        <contact:status s="linked"/>
+        <contact:status s="clientDeleteProhibited"/>
+        <contact:postalInfo type="int">
+          <contact:name>John Doe</contact:name>
+          <contact:org>Example Inc.</contact:org>
+          <contact:addr>
+            <contact:street>123 Example Dr.</contact:street>
+            <contact:street>Suite 100</contact:street>
+            <contact:street>Floor 2</contact:street>
+            <contact:city>Dulles</contact:city>
+            <contact:sp>VA</contact:sp>
+            <contact:pc>20166-6503</contact:pc>
+            <contact:cc>US</contact:cc>
+          </contact:addr>
+        </contact:postalInfo>
+        <contact:postalInfo type="loc">
+          <contact:name>Jean Dupont</contact:name>
+          <contact:addr>
+            <contact:street>1 rue de l'Exemple</contact:street>
+            <contact:city>Paris</contact:city>
+            <contact:cc>FR</contact:cc>
+          </contact:addr>
+        </contact:postalInfo>
+        <contact:voice x="1234">+1.7035555555</contact:voice>
+        <contact:fax>+1.7035555556</contact:fax>
+        <contact:email>jdoe@example.com</contact:email>
+        <contact:clID>ClientY</contact:clID>
+        <contact:crID>ClientX</contact:crID>
+        <contact:crDate>1999-04-03T22:00:00.0Z</contact:crDate>
+        <contact:upID>ClientX</contact:upID>
+        <contact:upDate>1999-12-03T09:00:00.0Z</contact:upDate>
+        <contact:trDate>2000-04-08T09:00:00.0Z</contact:trDate>
+        <contact:authInfo>
+          <contact:pw>2fooBAR</contact:pw>
+        </contact:authInfo>
+        <contact:disclose flag="0">
+          <contact:name type="loc"/>
+          <contact:voice/>
+          <contact:email/>
+        </contact:disclose>
+      </contact:infData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	cir := &res.ContactInfoResponse
+	st.Expect(t, cir.ID, "sh8013")
+	st.Expect(t, cir.ROID, "SH8013-REP")
+	st.Expect(t, cir.Status, []string{"linked", "clientDeleteProhibited"})
+	st.Expect(t, cir.Postal, []PostalInfo{
+		{
+			Type:    "int",
+			Name:    "John Doe",
+			Org:     "Example Inc.",
+			Street:  "123 Example Dr.",
+			Street2: "Suite 100",
+			Street3: "Floor 2",
+			City:    "Dulles",
+			SP:      "VA",
+			PC:      "20166-6503",
+			CC:      "US",
+		},
+		{
+			Type:   "loc",
+			Name:   "Jean Dupont",
+			Street: "1 rue de l'Exemple",
+			City:   "Paris",
+			CC:     "FR",
+		},
+	})
+	st.Expect(t, cir.Voice, "+1.7035555555")
+	st.Expect(t, cir.VoiceExt, "1234")
+	st.Expect(t, cir.Fax, "+1.7035555556")
+	st.Expect(t, cir.Email, "jdoe@example.com")
+	st.Expect(t, cir.ClID, "ClientY")
+	st.Expect(t, cir.CrID, "ClientX")
+	st.Expect(t, cir.UpID, "ClientX")
+	st.Expect(t, cir.CrDate, time.Date(1999, 4, 3, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.UpDate, time.Date(1999, 12, 3, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.TrDate, time.Date(2000, 4, 8, 9, 0, 0, 0, time.UTC))
+	st.Expect(t, cir.AuthInfo, "2fooBAR")
+	st.Expect(t, cir.Disclose, &Disclose{Name: []string{"loc"}, Voice: true, Email: true})
+}
@@ -0,0 +1,96 @@
+package epp
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// ErrServerClosed is returned by commands on a Conn after the server ended
+// the session, either with a result code of 2500 or above, or by closing the
+// connection, as registries do with sessions left idle. It matches ErrBroken:
+// the Conn must be replaced by a new connection and login.
+var ErrServerClosed error = serverClosedError{}
+
+type serverClosedError struct{}
+
+func (serverClosedError) Error() string {
+	return "epp: session closed by server"
+}
+
+// Is reports whether target is ErrBroken.
+func (serverClosedError) Is(target error) bool {
+	return target == ErrBroken
+}
+
+// Done returns a channel that is closed when c can no longer be used,
+// because it was closed, broken by an interrupted frame, or closed by
+// the server. A caller can wait on it to reconnect promptly.
+func (c *Conn) Done() <-chan struct{} {
+	return c.unusable
+}
+
+// Err returns nil while c can be used. Otherwise it returns why not:
+// net.ErrClosed if c was closed, ErrServerClosed if the server ended the
+// session, or ErrBroken.
+func (c *Conn) Err() error {
+	select {
+	case <-c.done:
+		return net.ErrClosed
+	default:
+	}
+	if c.broken.Load() {
+		return c.brokenErr()
+	}
+	return nil
+}
+
+// brokenErr returns the error for commands on c once it is broken.
+func (c *Conn) brokenErr() error {
+	if c.serverClosed.Load() {
+		return ErrServerClosed
+	}
+	return ErrBroken
+}
+
+// markServerClosed marks c as closed by the server, failing any commands
+// still awaiting a response.
+func (c *Conn) markServerClosed() {
+	c.serverClosed.Store(true)
+	c.markBroken()
+	c.failPending(ErrServerClosed)
+}
+
+// KeepAlive sends a <hello> on c whenever no command has been written for
+// interval, so that the server does not close the session as idle. It runs
+// in the background until c is closed or can no longer be used. If a hello
+// fails, c is marked unusable, which is reported by Done and Err.
+// KeepAlive should be called at most once.
+func (c *Conn) KeepAlive(interval time.Duration) {
+	go c.keepAlive(interval)
+}
+
+func (c *Conn) keepAlive(interval time.Duration) {
+	t := time.NewTimer(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.unusable:
+			return
+		case <-t.C:
+		}
+		idle := time.Since(time.Unix(0, c.lastWrite.Load()))
+		if idle < interval {
+			t.Reset(interval - idle)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := c.HelloContext(ctx)
+		cancel()
+		if err != nil {
+			c.markBroken()
+			return
+		}
+		t.Reset(interval)
+	}
+}
@@ -0,0 +1,173 @@
+package epp
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestConnServerClosedWithResult(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		writeDataUnit(conn, []byte(`<epp><response><result code="2500"><msg>Command failed; server closing connection</msg></result></response></epp>`))
+		io.Copy(io.Discard, conn)
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	st.Expect(t, c.Err(), nil)
+
+	_, err = c.CheckDomain("example.com")
+	st.Expect(t, errors.Is(err, ErrCommandFailedClosing), true)
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done not closed after 2500 result")
+	}
+	st.Expect(t, c.Err(), ErrServerClosed)
+	st.Expect(t, errors.Is(c.Err(), ErrBroken), true)
+
+	err = c.Hello()
+	st.Expect(t, err, ErrServerClosed)
+	st.Expect(t, IsTransient(err), true)
+	st.Expect(t, c.Close(), nil)
+	st.Expect(t, c.Err(), net.ErrClosed)
+}
+
+func TestConnServerClosedIdle(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		conn.Close()
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+
+	err = c.Hello()
+	st.Expect(t, errors.Is(err, ErrServerClosed), true)
+	st.Expect(t, errors.Is(err, io.EOF), true)
+	<-c.Done()
+	st.Expect(t, c.Err(), ErrServerClosed)
+}
+
+func TestConnKeepAlive(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var hellos atomic.Int32
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		for {
+			x, err := readTestRequest(conn)
+			if err != nil {
+				return
+			}
+			if strings.Contains(string(x), "<hello/>") {
+				if hellos.Add(1) == 3 {
+					// Close the session, as a registry would.
+					return
+				}
+				writeDataUnit(conn, []byte(testXMLGreeting))
+				continue
+			}
+			writeDataUnit(conn, []byte(testXMLResultOK))
+		}
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	c.KeepAlive(30 * time.Millisecond)
+
+	// Commands count as activity, so no hello is sent while they continue.
+	for i := 0; i < 5; i++ {
+		_, err = c.CheckDomain("example.com")
+		st.Assert(t, err, nil)
+		time.Sleep(10 * time.Millisecond)
+	}
+	st.Expect(t, hellos.Load(), int32(0))
+
+	// Idle, hellos are sent until the server closes the session.
+	select {
+	case <-c.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done not closed after the server closed the session")
+	}
+	st.Expect(t, hellos.Load(), int32(3))
+	st.Expect(t, errors.Is(c.Err(), ErrBroken), true)
+}
+
+func TestConnKeepAliveAfterTimeout(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	sent := make(chan struct{})
+	var hellos atomic.Int32
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		readTestRequest(conn)
+		// Send part of the response, then the rest after the client times out.
+		x := []byte(testXMLResultOK)
+		binary.Write(conn, binary.BigEndian, uint32(4+len(x)))
+		conn.Write(x[:10])
+		time.Sleep(300 * time.Millisecond)
+		conn.Write(x[10:])
+		close(sent)
+		for {
+			x, err := readTestRequest(conn)
+			if err != nil {
+				return
+			}
+			if strings.Contains(string(x), "<hello/>") {
+				hellos.Add(1)
+				writeDataUnit(conn, []byte(testXMLGreeting))
+			}
+		}
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewTimeoutConn(nc, 100*time.Millisecond)
+	st.Assert(t, err, nil)
+
+	_, err = c.CheckDomain("example.com")
+	st.Expect(t, errors.Is(err, os.ErrDeadlineExceeded), true)
+	<-sent
+	c.KeepAlive(20 * time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for hellos.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	st.Expect(t, hellos.Load() >= 3, true)
+	st.Expect(t, c.Err(), nil)
+	c.Close()
+}
@@ -0,0 +1,386 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"github.com/nbio/xx"
+)
+
+// ExtSignedMark is the namespace of the signed mark data (SMD) carried in a
+// launch create command.
+// https://tools.ietf.org/html/rfc7848
+const ExtSignedMark = "urn:ietf:params:xml:ns:signedMark-1.0"
+
+// Launch check types.
+// https://tools.ietf.org/html/rfc8334#section-3.1
+const (
+	LaunchCheckClaims    = "claims"    // claims check form: returns claim keys
+	LaunchCheckAvail     = "avail"     // availability check form
+	LaunchCheckTrademark = "trademark" // trademark check form
+)
+
+// LaunchCheck holds the launch extension parameters of a domain check.
+// https://tools.ietf.org/html/rfc8334#section-3.1
+type LaunchCheck struct {
+	Type      string // LaunchCheckClaims (default), LaunchCheckAvail or LaunchCheckTrademark
+	Phase     string // <launch:phase>, optional for the claims and trademark forms
+	PhaseName string // name attribute of a custom phase
+}
+
+// LaunchClaim is a <launch:cd> element of a claims or trademark check response.
+type LaunchClaim struct {
+	Domain    string     // <launch:name>
+	Exists    bool       // exists attribute: true if a trademark matches
+	ClaimKeys []ClaimKey // <launch:claimKey>
+}
+
+// ClaimKey is a key to retrieve a trademark claims notice from a validator.
+type ClaimKey struct {
+	Key         string // <launch:claimKey>
+	ValidatorID string // validatorID attribute; empty means the TMCH
+}
+
+// LaunchCreate holds the launch extension parameters of a domain create.
+// https://tools.ietf.org/html/rfc8334#section-3.3
+type LaunchCreate struct {
+	Phase     string         // <launch:phase>, required
+	PhaseName string         // name attribute of a custom phase
+	Type      string         // type attribute, "application" or "registration"
+	SMD       string         // <smd:encodedSignedMark>: base64 encoded signed mark data, without the BEGIN/END lines
+	Notices   []LaunchNotice // <launch:notice>: accepted trademark claims notices; not with SMD
+}
+
+// LaunchNotice is an accepted trademark claims notice.
+type LaunchNotice struct {
+	NoticeID     string    // <launch:noticeID>
+	ValidatorID  string    // validatorID attribute of noticeID
+	NotAfter     time.Time // <launch:notAfter>
+	AcceptedDate time.Time // <launch:acceptedDate>
+}
+
+// Validate returns a ValidationError if lc is not a valid launch create extension.
+func (lc *LaunchCreate) Validate() error {
+	if err := validateRequired("Launch.Phase", lc.Phase); err != nil {
+		return err
+	}
+	switch lc.Type {
+	case "", "application", "registration":
+	default:
+		return invalid("Launch.Type", "%q is not application or registration", lc.Type)
+	}
+	if lc.SMD != "" && len(lc.Notices) > 0 {
+		return invalid("Launch.Notices", "cannot be combined with an SMD")
+	}
+	for _, n := range lc.Notices {
+		if err := validateRequired("Launch.Notices", n.NoticeID); err != nil {
+			return err
+		}
+		if n.NotAfter.IsZero() || n.AcceptedDate.IsZero() {
+			return invalid("Launch.Notices", "notice %s needs notAfter and acceptedDate", n.NoticeID)
+		}
+	}
+	return nil
+}
+
+// LaunchApplication identifies a launch application, for the launch info,
+// update and delete commands.
+// https://tools.ietf.org/html/rfc8334#section-3.2
+type LaunchApplication struct {
+	Phase         string // <launch:phase>, required
+	PhaseName     string // name attribute of a custom phase
+	ApplicationID string // <launch:applicationID>
+}
+
+func (app *LaunchApplication) validate(needID bool) error {
+	if err := validateRequired("Launch.Phase", app.Phase); err != nil {
+		return err
+	}
+	if needID {
+		return validateRequired("Launch.ApplicationID", app.ApplicationID)
+	}
+	return nil
+}
+
+// LaunchInfo is the <launch:infData> of a domain info response.
+type LaunchInfo struct {
+	Phase         string // <launch:phase>
+	PhaseName     string // name attribute of a custom phase
+	ApplicationID string // <launch:applicationID>
+	Status        string // <launch:status s="...">, e.g. "pendingValidation"
+	StatusName    string // name attribute of a custom status
+}
+
+// CheckDomainClaims performs a claims check on domains, returning whether a
+// trademark matches each domain and the keys of the claims notices.
+// https://tools.ietf.org/html/rfc8334#section-3.1.1
+func (c *Conn) CheckDomainClaims(domains ...string) (*DomainCheckResponse, error) {
+	return c.CheckDomainLaunchContext(context.Background(), domains, &LaunchCheck{Type: LaunchCheckClaims})
+}
+
+// CheckDomainClaimsContext is like CheckDomainClaims, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckDomainClaimsContext(ctx context.Context, domains ...string) (*DomainCheckResponse, error) {
+	return c.CheckDomainLaunchContext(ctx, domains, &LaunchCheck{Type: LaunchCheckClaims})
+}
+
+// CheckDomainLaunch performs a domain check with the launch extension.
+// https://tools.ietf.org/html/rfc8334#section-3.1
+func (c *Conn) CheckDomainLaunch(domains []string, lc *LaunchCheck) (*DomainCheckResponse, error) {
+	return c.CheckDomainLaunchContext(context.Background(), domains, lc)
+}
+
+// CheckDomainLaunchContext is like CheckDomainLaunch, but honors cancellation and deadlines from ctx.
+func (c *Conn) CheckDomainLaunchContext(ctx context.Context, domains []string, lc *LaunchCheck) (*DomainCheckResponse, error) {
+	x, err := encodeDomainLaunchCheck(&c.Greeting, domains, lc)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainCheckResponse, nil
+}
+
+func encodeDomainLaunchCheck(greeting *Greeting, domains []string, lc *LaunchCheck) ([]byte, error) {
+	typ := lc.Type
+	switch typ {
+	case "":
+		typ = LaunchCheckClaims
+	case LaunchCheckClaims, LaunchCheckAvail, LaunchCheckTrademark:
+	default:
+		return nil, invalid("Launch.Type", "%q is not claims, avail or trademark", lc.Type)
+	}
+	if typ == LaunchCheckAvail && lc.Phase == "" {
+		return nil, invalid("Launch.Phase", "required for an avail check")
+	}
+	if err := validateHostNames("Domains", domains); err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
+	for _, domain := range domains {
+		buf.WriteString(`<domain:name>`)
+		xml.EscapeText(buf, []byte(domain))
+		buf.WriteString(`</domain:name>`)
+	}
+	buf.WriteString(`</domain:check></check>`)
+	buf.WriteString(`<extension><launch:check xmlns:launch="`)
+	buf.WriteString(ExtLaunch)
+	buf.WriteString(`" type="`)
+	buf.WriteString(typ)
+	buf.WriteString(`">`)
+	if lc.Phase != "" {
+		encodeLaunchPhase(buf, lc.Phase, lc.PhaseName)
+	}
+	buf.WriteString(`</launch:check></extension>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// DomainInfoLaunch retrieves the launch information of a domain or of the
+// launch application identified by app.
+// https://tools.ietf.org/html/rfc8334#section-3.2
+func (c *Conn) DomainInfoLaunch(domain string, app *LaunchApplication) (*DomainInfoResponse, error) {
+	return c.DomainInfoLaunchContext(context.Background(), domain, app)
+}
+
+// DomainInfoLaunchContext is like DomainInfoLaunch, but honors cancellation and deadlines from ctx.
+func (c *Conn) DomainInfoLaunchContext(ctx context.Context, domain string, app *LaunchApplication) (*DomainInfoResponse, error) {
+	x, err := encodeDomainLaunchInfo(&c.Greeting, domain, app)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainInfoResponse, nil
+}
+
+func encodeDomainLaunchInfo(greeting *Greeting, domain string, app *LaunchApplication) ([]byte, error) {
+	if err := validateRequired("Domain", domain); err != nil {
+		return nil, err
+	}
+	if err := app.validate(false); err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>`)
+	xml.EscapeText(buf, []byte(domain))
+	buf.WriteString(`</domain:name></domain:info></info>`)
+	buf.WriteString(`<extension>`)
+	encodeLaunchApplication(buf, "info", app)
+	buf.WriteString(`</extension>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// DeleteDomainLaunch requests the deletion of the launch application
+// identified by app. It returns the transaction identifiers of the response.
+// https://tools.ietf.org/html/rfc8334#section-3.5
+func (c *Conn) DeleteDomainLaunch(domain string, app *LaunchApplication) (*TransactionID, error) {
+	return c.DeleteDomainLaunchContext(context.Background(), domain, app)
+}
+
+// DeleteDomainLaunchContext is like DeleteDomainLaunch, but honors cancellation and deadlines from ctx.
+func (c *Conn) DeleteDomainLaunchContext(ctx context.Context, domain string, app *LaunchApplication) (*TransactionID, error) {
+	x, err := encodeDomainLaunchDelete(&c.Greeting, domain, app)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Result.TransactionID, nil
+}
+
+func encodeDomainLaunchDelete(greeting *Greeting, domain string, app *LaunchApplication) ([]byte, error) {
+	if err := validateRequired("Domain", domain); err != nil {
+		return nil, err
+	}
+	if err := app.validate(true); err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<delete><domain:delete xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>`)
+	xml.EscapeText(buf, []byte(domain))
+	buf.WriteString(`</domain:name></domain:delete></delete>`)
+	buf.WriteString(`<extension>`)
+	encodeLaunchApplication(buf, "delete", app)
+	buf.WriteString(`</extension>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// encodeLaunchPhase writes a <launch:phase> element.
+func encodeLaunchPhase(buf *bytes.Buffer, phase, name string) {
+	buf.WriteString(`<launch:phase`)
+	if name != "" {
+		buf.WriteString(` name="`)
+		xml.EscapeText(buf, []byte(name))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(`>`)
+	xml.EscapeText(buf, []byte(phase))
+	buf.WriteString(`</launch:phase>`)
+}
+
+// encodeLaunchApplication writes a <launch:info>, <launch:update> or
+// <launch:delete> element identifying app.
+func encodeLaunchApplication(buf *bytes.Buffer, op string, app *LaunchApplication) {
+	buf.WriteString(`<launch:` + op + ` xmlns:launch="`)
+	buf.WriteString(ExtLaunch)
+	buf.WriteString(`">`)
+	encodeLaunchPhase(buf, app.Phase, app.PhaseName)
+	if app.ApplicationID != "" {
+		buf.WriteString(`<launch:applicationID>`)
+		xml.EscapeText(buf, []byte(app.ApplicationID))
+		buf.WriteString(`</launch:applicationID>`)
+	}
+	buf.WriteString(`</launch:` + op + `>`)
+}
+
+// encodeLaunchCreate writes a <launch:create> element.
+func encodeLaunchCreate(buf *bytes.Buffer, lc *LaunchCreate) {
+	buf.WriteString(`<launch:create xmlns:launch="`)
+	buf.WriteString(ExtLaunch)
+	buf.WriteString(`"`)
+	if lc.Type != "" {
+		buf.WriteString(` type="`)
+		buf.WriteString(lc.Type)
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(`>`)
+	encodeLaunchPhase(buf, lc.Phase, lc.PhaseName)
+	if lc.SMD != "" {
+		buf.WriteString(`<smd:encodedSignedMark xmlns:smd="`)
+		buf.WriteString(ExtSignedMark)
+		buf.WriteString(`">`)
+		xml.EscapeText(buf, []byte(strings.TrimSpace(lc.SMD)))
+		buf.WriteString(`</smd:encodedSignedMark>`)
+	}
+	for _, n := range lc.Notices {
+		buf.WriteString(`<launch:notice><launch:noticeID`)
+		if n.ValidatorID != "" {
+			buf.WriteString(` validatorID="`)
+			xml.EscapeText(buf, []byte(n.ValidatorID))
+			buf.WriteString(`"`)
+		}
+		buf.WriteString(`>`)
+		xml.EscapeText(buf, []byte(n.NoticeID))
+		buf.WriteString(`</launch:noticeID><launch:notAfter>`)
+		buf.WriteString(n.NotAfter.UTC().Format(time.RFC3339))
+		buf.WriteString(`</launch:notAfter><launch:acceptedDate>`)
+		buf.WriteString(n.AcceptedDate.UTC().Format(time.RFC3339))
+		buf.WriteString(`</launch:acceptedDate></launch:notice>`)
+	}
+	buf.WriteString(`</launch:create>`)
+}
+
+func init() {
+	// Claims and trademark check responses
+	path := "epp > response > extension > " + ExtLaunch + " chkData"
+	scanResponse.MustHandleCharData(path+">phase", func(c *xx.Context) error {
+		c.Value.(*Response).DomainCheckResponse.LaunchPhase = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">cd", func(c *xx.Context) error {
+		dcr := &c.Value.(*Response).DomainCheckResponse
+		dcr.Claims = append(dcr.Claims, LaunchClaim{})
+		return nil
+	})
+	lastClaim := func(c *xx.Context) *LaunchClaim {
+		claims := c.Value.(*Response).DomainCheckResponse.Claims
+		return &claims[len(claims)-1]
+	}
+	scanResponse.MustHandleCharData(path+">cd>name", func(c *xx.Context) error {
+		claim := lastClaim(c)
+		claim.Domain = string(c.CharData)
+		claim.Exists = c.AttrBool("", "exists")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">cd>claimKey", func(c *xx.Context) error {
+		claim := lastClaim(c)
+		claim.ClaimKeys = append(claim.ClaimKeys, ClaimKey{
+			Key:         string(c.CharData),
+			ValidatorID: c.Attr("", "validatorID"),
+		})
+		return nil
+	})
+
+	// Create responses
+	path = "epp > response > extension > " + ExtLaunch + " creData"
+	scanResponse.MustHandleCharData(path+">phase", func(c *xx.Context) error {
+		c.Value.(*Response).DomainCreateResponse.LaunchPhase = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">applicationID", func(c *xx.Context) error {
+		c.Value.(*Response).DomainCreateResponse.ApplicationID = string(c.CharData)
+		return nil
+	})
+
+	// Info responses
+	path = "epp > response > extension > " + ExtLaunch + " infData"
+	scanResponse.MustHandleCharData(path+">phase", func(c *xx.Context) error {
+		li := &c.Value.(*Response).DomainInfoResponse.Launch
+		li.Phase = string(c.CharData)
+		li.PhaseName = c.Attr("", "name")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">applicationID", func(c *xx.Context) error {
+		c.Value.(*Response).DomainInfoResponse.Launch.ApplicationID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">status", func(c *xx.Context) error {
+		li := &c.Value.(*Response).DomainInfoResponse.Launch
+		li.Status = c.Attr("", "s")
+		li.StatusName = c.Attr("", "name")
+		return nil
+	})
+}
@@ -0,0 +1,203 @@
+package epp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeDomainLaunchCheck(t *testing.T) {
+	x, err := encodeDomainLaunchCheck(nil, []string{"example.com", "example.net"}, &LaunchCheck{Phase: "claims"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:name>example.net</domain:name></domain:check></check><extension><launch:check xmlns:launch="urn:ietf:params:xml:ns:launch-1.0" type="claims"><launch:phase>claims</launch:phase></launch:check></extension></command></epp>`)
+
+	x, err = encodeDomainLaunchCheck(nil, []string{"example.com"}, &LaunchCheck{Type: LaunchCheckAvail, Phase: "custom", PhaseName: "landrush"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:check></check><extension><launch:check xmlns:launch="urn:ietf:params:xml:ns:launch-1.0" type="avail"><launch:phase name="landrush">custom</launch:phase></launch:check></extension></command></epp>`)
+
+	var verr *ValidationError
+	_, err = encodeDomainLaunchCheck(nil, []string{"example.com"}, &LaunchCheck{Type: LaunchCheckAvail})
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Launch.Phase")
+}
+
+func TestScanLaunchCheckResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <extension>
+      <launch:chkData xmlns:launch="urn:ietf:params:xml:ns:launch-1.0">
+        <launch:phase>claims</launch:phase>
+        <launch:cd>
+          <launch:name exists="0">example1.com</launch:name>
+        </launch:cd>
+        <launch:cd>
+          <launch:name exists="1">example2.com</launch:name>
+          <launch:claimKey validatorID="tmch">2013041500/2/6/9/rJ1NrDO92vDsAzf7EQzgjX4R0000000001</launch:claimKey>
+          <launch:claimKey validatorID="custom-tmch">20140423200/1/2/3/rJ1Nr2vDsAzasdff7EasdfgjX4R000000002</launch:claimKey>
+        </launch:cd>
+      </launch:chkData>
+    </extension>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54321-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	dcr := &res.DomainCheckResponse
+	st.Expect(t, dcr.LaunchPhase, "claims")
+	st.Expect(t, dcr.Claims, []LaunchClaim{
+		{Domain: "example1.com"},
+		{Domain: "example2.com", Exists: true, ClaimKeys: []ClaimKey{
+			{Key: "2013041500/2/6/9/rJ1NrDO92vDsAzf7EQzgjX4R0000000001", ValidatorID: "tmch"},
+			{Key: "20140423200/1/2/3/rJ1Nr2vDsAzasdff7EasdfgjX4R000000002", ValidatorID: "custom-tmch"},
+		}},
+	})
+}
+
+func TestDomainCreateLaunch(t *testing.T) {
+	dc := &DomainCreate{
+		Domain: "example.com",
+		Launch: &LaunchCreate{
+			Phase: "sunrise",
+			Type:  "application",
+			SMD:   "\nPD94bWwgdmVyc2lvbj0iMS4wIiBlbmNvZGluZz0iVVRGLTgiPz4=\n",
+		},
+	}
+	x, err := dc.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create><extension><launch:create xmlns:launch="urn:ietf:params:xml:ns:launch-1.0" type="application"><launch:phase>sunrise</launch:phase><smd:encodedSignedMark xmlns:smd="urn:ietf:params:xml:ns:signedMark-1.0">PD94bWwgdmVyc2lvbj0iMS4wIiBlbmNvZGluZz0iVVRGLTgiPz4=</smd:encodedSignedMark></launch:create></extension></command></epp>`)
+
+	dc.Launch = &LaunchCreate{
+		Phase: "claims",
+		Notices: []LaunchNotice{{
+			NoticeID:     "370d0b7c9223372036854775807",
+			ValidatorID:  "tmch",
+			NotAfter:     time.Date(2010, 8, 16, 9, 0, 0, 0, time.UTC),
+			AcceptedDate: time.Date(2009, 8, 16, 9, 0, 0, 0, time.UTC),
+		}},
+	}
+	x, err = dc.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create><extension><launch:create xmlns:launch="urn:ietf:params:xml:ns:launch-1.0"><launch:phase>claims</launch:phase><launch:notice><launch:noticeID validatorID="tmch">370d0b7c9223372036854775807</launch:noticeID><launch:notAfter>2010-08-16T09:00:00Z</launch:notAfter><launch:acceptedDate>2009-08-16T09:00:00Z</launch:acceptedDate></launch:notice></launch:create></extension></command></epp>`)
+
+	var verr *ValidationError
+	dc.Launch = &LaunchCreate{Phase: "claims", Notices: []LaunchNotice{{NoticeID: "abc"}}}
+	_, err = dc.encode(nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Launch.Notices")
+
+	notice := LaunchNotice{NoticeID: "abc", NotAfter: time.Now(), AcceptedDate: time.Now()}
+	dc.Launch = &LaunchCreate{Phase: "sunrise", SMD: "PD94", Notices: []LaunchNotice{notice}}
+	_, err = dc.encode(nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Launch.Notices")
+}
+
+func TestScanLaunchCreateResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1001">
+      <msg>Command completed successfully; action pending</msg>
+    </result>
+    <resData>
+      <domain:creData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:crDate>2010-08-10T15:38:26.623854Z</domain:crDate>
+      </domain:creData>
+    </resData>
+    <extension>
+      <launch:creData xmlns:launch="urn:ietf:params:xml:ns:launch-1.0">
+        <launch:phase>sunrise</launch:phase>
+        <launch:applicationID>2393-9323-E08C-03B1</launch:applicationID>
+      </launch:creData>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainCreateResponse.Domain, "example.com")
+	st.Expect(t, res.DomainCreateResponse.LaunchPhase, "sunrise")
+	st.Expect(t, res.DomainCreateResponse.ApplicationID, "2393-9323-E08C-03B1")
+}
+
+func TestEncodeDomainLaunchInfo(t *testing.T) {
+	x, err := encodeDomainLaunchInfo(nil, "example.com", &LaunchApplication{Phase: "sunrise", ApplicationID: "abc123"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:info></info><extension><launch:info xmlns:launch="urn:ietf:params:xml:ns:launch-1.0"><launch:phase>sunrise</launch:phase><launch:applicationID>abc123</launch:applicationID></launch:info></extension></command></epp>`)
+}
+
+func TestScanLaunchInfoResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <resData>
+      <domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+      </domain:infData>
+    </resData>
+    <extension>
+      <launch:infData xmlns:launch="urn:ietf:params:xml:ns:launch-1.0">
+        <launch:phase name="landrush">custom</launch:phase>
+        <launch:applicationID>abc123</launch:applicationID>
+        <launch:status s="pendingValidation"/>
+      </launch:infData>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainInfoResponse.Launch, LaunchInfo{
+		Phase:         "custom",
+		PhaseName:     "landrush",
+		ApplicationID: "abc123",
+		Status:        "pendingValidation",
+	})
+}
+
+func TestDomainUpdateLaunch(t *testing.T) {
+	du := &DomainUpdate{
+		Domain: "example.com",
+		Add:    DomainAddRem{Nameservers: []string{"ns2.example.com"}},
+		Launch: &LaunchApplication{Phase: "sunrise", ApplicationID: "abc123"},
+	}
+	x, err := du.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:add><domain:ns><domain:hostObj>ns2.example.com</domain:hostObj></domain:ns></domain:add></domain:update></update><extension><launch:update xmlns:launch="urn:ietf:params:xml:ns:launch-1.0"><launch:phase>sunrise</launch:phase><launch:applicationID>abc123</launch:applicationID></launch:update></extension></command></epp>`)
+
+	var verr *ValidationError
+	du.Launch.ApplicationID = ""
+	_, err = du.encode(nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Launch.ApplicationID")
+}
+
+func TestEncodeDomainLaunchDelete(t *testing.T) {
+	x, err := encodeDomainLaunchDelete(nil, "example.com", &LaunchApplication{Phase: "sunrise", ApplicationID: "abc123"})
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><delete><domain:delete xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:delete></delete><extension><launch:delete xmlns:launch="urn:ietf:params:xml:ns:launch-1.0"><launch:phase>sunrise</launch:phase><launch:applicationID>abc123</launch:applicationID></launch:delete></extension></command></epp>`)
+}
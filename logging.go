@@ -2,46 +2,236 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 )
 
 // DebugLogger is an io.Writer. Set to enable logging of EPP message XML.
+// It applies to every Conn in the process.
+//
+// Deprecated: Set Conn.Logger instead, which is per connection and logs
+// each command as a structured event.
 var DebugLogger io.Writer
 
+// Redact controls the masking of secrets in XML written to DebugLogger
+// or to a Conn.Logger.
+// If true (the default), the text of password, authInfo and allocation
+// token elements, and of elements matching RedactPaths, is replaced with
+// "[REDACTED]".
+var Redact = true
+
+// RedactPaths lists further elements to redact, such as contact PII.
+// Each is a path of element names separated by "/", matched against the
+// end of an element's path; a leading "/" anchors it at the root element.
+// A name may include a namespace prefix ("contact:email") or be "*" to
+// match any element. For example: "postalInfo/name", "contact:email".
+var RedactPaths []string
+
+// defaultRedactPaths lists the elements holding credentials.
+var defaultRedactPaths = []string{"pw", "newPW", "authInfo", "allocationToken:allocationToken"}
+
+// redacted replaces the text of redacted elements.
+const redacted = "[REDACTED]"
+
 func logXML(pfx string, p []byte) {
 	if DebugLogger == nil {
 		return
 	}
+	b, err := formatXML(p)
+	if err != nil {
+		if Redact {
+			// The raw XML cannot be redacted, so it is not logged.
+			fmt.Fprintf(DebugLogger, "Indentation error. Raw XML not shown, as it cannot be redacted: %s (%d bytes): %v\n\n", pfx, len(p), err)
+			return
+		}
+		fmt.Fprintf(DebugLogger, "Indentation error. Raw XML: %s\n%s\n\n", pfx, string(p))
+		return
+	}
+
+	fmt.Fprintf(DebugLogger, "%s (pretty-printed)\n", pfx)
+	DebugLogger.Write(b)
+	fmt.Fprint(DebugLogger, "\n\n")
+}
+
+// formatXML returns p indented, with secrets redacted if Redact is set.
+func formatXML(p []byte) ([]byte, error) {
+	var r *redactor
+	if Redact {
+		r = newRedactor(append(defaultRedactPaths, RedactPaths...))
+	}
 
 	var b bytes.Buffer
 	enc := xml.NewEncoder(&b)
 	enc.Indent("", "\t")
 
 	dec := xml.NewDecoder(bytes.NewReader(p))
-	var t xml.Token
-	var err error
 	for {
-		t, err = dec.RawToken()
+		t, err := dec.RawToken()
 		if err == io.EOF {
 			err = enc.Flush()
-			break
+			return b.Bytes(), err
 		}
 		if err != nil {
-			break
+			return nil, err
+		}
+		if r != nil {
+			t = r.token(t)
 		}
 		err = enc.EncodeToken(t)
 		if err != nil {
-			break
+			return nil, err
 		}
 	}
+}
+
+// debugXML logs the XML of a request or response to c.Logger at debug level.
+func (c *Conn) debugXML(ctx context.Context, msg string, p []byte) {
+	if c.Logger == nil || !c.Logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	b, err := formatXML(p)
 	if err != nil {
-		fmt.Fprintf(DebugLogger, "Indentation error. Raw XML: %s\n%s\n\n", pfx, string(p))
+		if Redact {
+			c.Logger.DebugContext(ctx, msg, "size", len(p), "error", err)
+		} else {
+			c.Logger.DebugContext(ctx, msg, "size", len(p), "error", err, "xml", string(p))
+		}
 		return
 	}
+	c.Logger.DebugContext(ctx, msg, "xml", string(b))
+}
 
-	fmt.Fprintf(DebugLogger, "%s (pretty-printed)\n", pfx)
-	io.Copy(DebugLogger, &b)
-	fmt.Fprint(DebugLogger, "\n\n")
+// logCommand logs the outcome of a command to c.Logger: at info level
+// if it succeeded, warning level if the server returned an error result,
+// and error level if no valid response was received.
+func (c *Conn) logCommand(ctx context.Context, info *CommandInfo, err error) {
+	if c.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("command", info.Command),
+		slog.String("object", info.Object),
+		slog.String("server", info.Server),
+		slog.String("cltrid", info.ClTRID),
+		slog.Duration("latency", info.Latency),
+		slog.Int("request_size", info.RequestSize),
+	}
+	level := slog.LevelInfo
+	if info.ResponseSize > 0 {
+		attrs = append(attrs, slog.Int("response_size", info.ResponseSize))
+	}
+	if r := info.Result; r != nil {
+		attrs = append(attrs, slog.String("svtrid", r.SvTRID), slog.Int("code", r.Code))
+		if r.IsError() {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("message", r.Message))
+		}
+	}
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	c.Logger.LogAttrs(ctx, level, "epp command", attrs...)
+}
+
+// commandName returns the command and object type of the request x,
+// such as "create" and "domain", from the element names and prefixes.
+// The object type is empty for commands such as <login> and <poll>.
+// Requests other than <command>, such as <hello>, return their element name.
+func commandName(x []byte) (cmd, obj string) {
+	dec := xml.NewDecoder(bytes.NewReader(x))
+	depth := 0
+	for {
+		t, err := dec.RawToken()
+		if err != nil {
+			return cmd, obj
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 2:
+				cmd = t.Name.Local
+				if cmd != "command" {
+					return cmd, ""
+				}
+			case depth == 3:
+				cmd = t.Name.Local
+			case depth == 4:
+				return cmd, t.Name.Space
+			}
+		case xml.EndElement:
+			depth--
+			if depth < 3 && cmd != "command" && cmd != "" {
+				return cmd, ""
+			}
+		}
+	}
+}
+
+// redactor masks the text of matching elements in a stream of raw tokens.
+type redactor struct {
+	paths [][]string
+	names []xml.Name // open elements; Space holds the namespace prefix
+	masks []bool     // whether each open element is redacted
+}
+
+func newRedactor(paths []string) *redactor {
+	r := &redactor{}
+	for _, path := range paths {
+		r.paths = append(r.paths, strings.Split(path, "/"))
+	}
+	return r
+}
+
+// token returns t, with its text replaced if it is inside a redacted element.
+func (r *redactor) token(t xml.Token) xml.Token {
+	switch t := t.(type) {
+	case xml.StartElement:
+		r.names = append(r.names, t.Name)
+		mask := len(r.masks) > 0 && r.masks[len(r.masks)-1]
+		for _, path := range r.paths {
+			mask = mask || r.match(path)
+		}
+		r.masks = append(r.masks, mask)
+	case xml.EndElement:
+		if len(r.names) > 0 {
+			r.names = r.names[:len(r.names)-1]
+			r.masks = r.masks[:len(r.masks)-1]
+		}
+	case xml.CharData:
+		if len(r.masks) > 0 && r.masks[len(r.masks)-1] && len(bytes.TrimSpace(t)) > 0 {
+			return xml.CharData(redacted)
+		}
+	}
+	return t
+}
+
+// match reports whether path matches the open elements.
+func (r *redactor) match(path []string) bool {
+	anchored := len(path) > 1 && path[0] == ""
+	if anchored {
+		path = path[1:]
+	}
+	if len(path) > len(r.names) || (anchored && len(path) != len(r.names)) {
+		return false
+	}
+	names := r.names[len(r.names)-len(path):]
+	for i, seg := range path {
+		name := names[i]
+		switch prefix, local, ok := strings.Cut(seg, ":"); {
+		case seg == "*":
+		case ok:
+			if name.Space != prefix || name.Local != local {
+				return false
+			}
+		case name.Local != seg:
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,147 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func captureLogXML(pfx string, x []byte) string {
+	var buf bytes.Buffer
+	DebugLogger = &buf
+	defer func() { DebugLogger = nil }()
+	logXML(pfx, x)
+	return buf.String()
+}
+
+func TestLogXMLRedactsSecrets(t *testing.T) {
+	x, err := encodeLogin("user123", "secret-pass", "new-secret", "1.0", "en", []string{ObjDomain}, nil)
+	st.Assert(t, err, nil)
+	out := captureLogXML("REQUEST", x)
+	st.Expect(t, strings.Contains(out, "<clID>user123</clID>"), true)
+	st.Expect(t, strings.Contains(out, "<pw>[REDACTED]</pw>"), true)
+	st.Expect(t, strings.Contains(out, "<newPW>[REDACTED]</newPW>"), true)
+	st.Expect(t, strings.Contains(out, "secret"), false)
+
+	x, err = encodeDomainInfo(&Greeting{}, "example.com", HostsAll, "2fooBAR", nil)
+	st.Assert(t, err, nil)
+	out = captureLogXML("REQUEST", x)
+	st.Expect(t, strings.Contains(out, "example.com"), true)
+	st.Expect(t, strings.Contains(out, "2fooBAR"), false)
+	st.Expect(t, strings.Contains(out, "[REDACTED]"), true)
+}
+
+func TestLogXMLRedactPaths(t *testing.T) {
+	RedactPaths = []string{"postalInfo/name", "contact:email", "/epp/response/result/*"}
+	defer func() { RedactPaths = nil }()
+
+	x := []byte(`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1000"><msg>Command completed successfully</msg></result><resData><contact:infData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:postalInfo type="int"><contact:name>John Doe</contact:name><contact:org>Example Inc.</contact:org></contact:postalInfo><contact:email>jdoe@example.com</contact:email><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo></contact:infData></resData></response></epp>`)
+	out := captureLogXML("RESPONSE", x)
+	for _, s := range []string{"sh8013", "Example Inc.", `code="1000"`} {
+		st.Expect(t, strings.Contains(out, s), true)
+	}
+	for _, s := range []string{"John Doe", "jdoe@example.com", "2fooBAR", "Command completed"} {
+		st.Expect(t, strings.Contains(out, s), false)
+	}
+}
+
+func TestLogXMLRedactDisabled(t *testing.T) {
+	Redact = false
+	defer func() { Redact = true }()
+	x, err := encodeLogin("user123", "secret-pass", "", "1.0", "en", nil, nil)
+	st.Assert(t, err, nil)
+	out := captureLogXML("REQUEST", x)
+	st.Expect(t, strings.Contains(out, "<pw>secret-pass</pw>"), true)
+}
+
+func TestLogXMLUnparseable(t *testing.T) {
+	out := captureLogXML("RESPONSE", []byte(`<epp><pw>secret</epp>`))
+	st.Expect(t, strings.Contains(out, "secret"), false)
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		x        string
+		cmd, obj string
+	}{
+		{`<?xml version="1.0" encoding="UTF-8"?><epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><hello/></epp>`, "hello", ""},
+		{`<epp><command><login><clID>user</clID></login></command></epp>`, "login", ""},
+		{`<epp><command><poll op="req"/><clTRID>1</clTRID></command></epp>`, "poll", ""},
+		{`<epp><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create></command></epp>`, "create", "domain"},
+		{`<epp><extension><custom/></extension></epp>`, "extension", ""},
+		{`not xml`, "", ""},
+	}
+	for _, tt := range tests {
+		cmd, obj := commandName([]byte(tt.x))
+		st.Expect(t, cmd, tt.cmd)
+		st.Expect(t, obj, tt.obj)
+	}
+}
+
+func TestConnLogger(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<domain:create") {
+			return `<epp><response><result code="2302"><msg>Object exists</msg></result><trID><clTRID>ABC-1</clTRID><svTRID>SV-1</svTRID></trID></response></epp>`
+		}
+		return testXMLResultOK
+	})
+
+	var buf bytes.Buffer
+	cfg := testPoolConfig(ls)
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	p := NewPool(cfg)
+	err = p.Do(context.Background(), func(c *Conn) error {
+		_, err := c.DomainCreateContext(WithTransactionID(context.Background(), "ABC-1"), &DomainCreate{Domain: "example.com", AuthInfo: "2fooBAR"})
+		return err
+	})
+	st.Expect(t, errors.Is(err, ErrObjectExists), true)
+	p.Close()
+
+	var commands []map[string]any
+	var xmlLogged bool
+	dec := json.NewDecoder(&buf)
+	for {
+		var e map[string]any
+		if err := dec.Decode(&e); err != nil {
+			break
+		}
+		switch e["msg"] {
+		case "epp command":
+			commands = append(commands, e)
+		case "epp request", "epp response":
+			xmlLogged = true
+			st.Expect(t, strings.Contains(e["xml"].(string), "2fooBAR"), false)
+			st.Expect(t, strings.Contains(e["xml"].(string), "password"), false)
+		}
+	}
+	st.Expect(t, xmlLogged, true)
+	st.Assert(t, len(commands), 3)
+
+	st.Expect(t, commands[0]["command"], "login")
+	st.Expect(t, commands[0]["level"], "INFO")
+	st.Expect(t, commands[0]["code"], float64(1000))
+
+	e := commands[1]
+	st.Expect(t, e["level"], "WARN")
+	st.Expect(t, e["command"], "create")
+	st.Expect(t, e["object"], "domain")
+	st.Expect(t, e["cltrid"], "ABC-1")
+	st.Expect(t, e["svtrid"], "SV-1")
+	st.Expect(t, e["code"], float64(2302))
+	st.Expect(t, e["message"], "Object exists")
+	st.Expect(t, e["request_size"].(float64) > 0, true)
+	st.Expect(t, e["response_size"].(float64) > 0, true)
+	_, ok := e["latency"]
+	st.Expect(t, ok, true)
+
+	st.Expect(t, commands[2]["command"], "logout")
+}
@@ -0,0 +1,142 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"runtime"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nbio/xx"
+)
+
+// loginSecPassword replaces a password in <pw> or <newPW> that is sent in
+// the loginSec extension instead.
+// https://tools.ietf.org/html/rfc8807#section-3.2
+const loginSecPassword = "[LOGIN-SECURITY]"
+
+// maxLoginPassword is the longest password allowed in <pw> and <newPW>
+// by the EPP base schema.
+const maxLoginPassword = 16
+
+// longPassword reports whether pw is too long for <pw> or <newPW>.
+// The schema limits its length in characters, not bytes.
+func longPassword(pw string) bool {
+	return utf8.RuneCountInString(pw) > maxLoginPassword
+}
+
+// UserAgent identifies the client software in the loginSec extension.
+// https://tools.ietf.org/html/rfc8807#section-4.1
+type UserAgent struct {
+	App  string // <loginSec:app>, e.g. "registrar-bot 1.2"
+	Tech string // <loginSec:tech>, e.g. "go1.22.1"
+	OS   string // <loginSec:os>, e.g. "linux/amd64"
+}
+
+// defaultUserAgent is sent if Conn.UserAgent is nil.
+var defaultUserAgent = UserAgent{
+	App:  "github.com/onasunnymorning/eppclient",
+	Tech: runtime.Version(),
+	OS:   runtime.GOOS + "/" + runtime.GOARCH,
+}
+
+// Values for the type attribute of a security event.
+// https://tools.ietf.org/html/rfc8807#section-3.1
+const (
+	SecurityEventPassword    = "password"    // the password expires
+	SecurityEventCertificate = "certificate" // the client certificate expires
+	SecurityEventNewPW       = "newPW"       // the new password does not meet the server policy
+	SecurityEventCipher      = "cipher"      // the TLS cipher is insecure or deprecated
+	SecurityEventTLSProtocol = "tlsProtocol" // the TLS protocol is insecure or deprecated
+	SecurityEventStat        = "stat"        // a security statistic, identified by Name
+	SecurityEventCustom      = "custom"      // a custom event, identified by Name
+)
+
+// Values for the level attribute of a security event.
+const (
+	SecurityLevelWarning = "warning"
+	SecurityLevelError   = "error"
+)
+
+// SecurityEvent represents a <loginSec:event> returned in a login response.
+// https://tools.ietf.org/html/rfc8807#section-3.1
+type SecurityEvent struct {
+	Type        string    // type attribute, e.g. SecurityEventPassword
+	Name        string    // name attribute, for stat and custom events
+	Level       string    // level attribute, SecurityLevelWarning or SecurityLevelError
+	ExDate      time.Time // exDate attribute, when the password or certificate expires
+	Value       string    // value attribute, e.g. the cipher or statistic value
+	Duration    string    // duration attribute (XML Schema duration) of a statistic
+	Lang        string    // lang attribute
+	Description string
+}
+
+// IsError reports whether e has level error, such as an expired password.
+func (e *SecurityEvent) IsError() bool {
+	return e.Level == SecurityLevelError
+}
+
+// encodeLoginSec writes a <loginSec:loginSec> extension holding ua and any
+// password too long for <pw> or <newPW>.
+// https://tools.ietf.org/html/rfc8807#section-4.1
+func encodeLoginSec(buf *bytes.Buffer, ua *UserAgent, password, newPassword string) {
+	buf.WriteString(`<extension><loginSec:loginSec xmlns:loginSec="`)
+	buf.WriteString(ExtLoginSec)
+	buf.WriteString(`">`)
+	if ua != nil {
+		buf.WriteString(`<loginSec:userAgent>`)
+		for _, el := range []struct{ name, value string }{
+			{"app", ua.App},
+			{"tech", ua.Tech},
+			{"os", ua.OS},
+		} {
+			if el.value == "" {
+				continue
+			}
+			buf.WriteString(`<loginSec:` + el.name + `>`)
+			xml.EscapeText(buf, []byte(el.value))
+			buf.WriteString(`</loginSec:` + el.name + `>`)
+		}
+		buf.WriteString(`</loginSec:userAgent>`)
+	}
+	if longPassword(password) {
+		buf.WriteString(`<loginSec:pw>`)
+		xml.EscapeText(buf, []byte(password))
+		buf.WriteString(`</loginSec:pw>`)
+	}
+	if longPassword(newPassword) {
+		buf.WriteString(`<loginSec:newPW>`)
+		xml.EscapeText(buf, []byte(newPassword))
+		buf.WriteString(`</loginSec:newPW>`)
+	}
+	buf.WriteString(`</loginSec:loginSec></extension>`)
+}
+
+func init() {
+	path := "epp > response > extension > " + ExtLoginSec + " loginSecData > event"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		res := c.Value.(*Response)
+		e := SecurityEvent{
+			Type:     c.Attr("", "type"),
+			Name:     c.Attr("", "name"),
+			Level:    c.Attr("", "level"),
+			Value:    c.Attr("", "value"),
+			Duration: c.Attr("", "duration"),
+			Lang:     c.Attr("", "lang"),
+		}
+		if exDate := c.Attr("", "exDate"); exDate != "" {
+			var err error
+			e.ExDate, err = time.Parse(time.RFC3339, exDate)
+			if err != nil {
+				return err
+			}
+		}
+		res.Result.SecurityEvents = append(res.Result.SecurityEvents, e)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path, func(c *xx.Context) error {
+		events := c.Value.(*Response).Result.SecurityEvents
+		events[len(events)-1].Description = string(c.CharData)
+		return nil
+	})
+}
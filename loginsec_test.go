@@ -0,0 +1,95 @@
+package epp
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestEncodeLoginSec(t *testing.T) {
+	ua := &UserAgent{App: "app 1.0", Tech: "go1.22", OS: "linux/amd64"}
+	x, err := encodeLoginUserAgent("user123", "this is a long passphrase", "another long passphrase", "1.0", "en", []string{ObjDomain}, []string{ExtLoginSec}, ua)
+	st.Expect(t, err, nil)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><login><clID>user123</clID><pw>[LOGIN-SECURITY]</pw><newPW>[LOGIN-SECURITY]</newPW><options><version>1.0</version><lang>en</lang></options><svcs><objURI>urn:ietf:params:xml:ns:domain-1.0</objURI><svcExtension><extURI>urn:ietf:params:xml:ns:epp:loginSec-1.0</extURI></svcExtension></svcs></login><extension><loginSec:loginSec xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0"><loginSec:userAgent><loginSec:app>app 1.0</loginSec:app><loginSec:tech>go1.22</loginSec:tech><loginSec:os>linux/amd64</loginSec:os></loginSec:userAgent><loginSec:pw>this is a long passphrase</loginSec:pw><loginSec:newPW>another long passphrase</loginSec:newPW></loginSec:loginSec></extension></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeLoginSecShortPassword(t *testing.T) {
+	x, err := encodeLoginUserAgent("user123", "pass123", "", "1.0", "en", nil, []string{ExtLoginSec}, &UserAgent{App: "app"})
+	st.Expect(t, err, nil)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><login><clID>user123</clID><pw>pass123</pw><options><version>1.0</version><lang>en</lang></options><svcs><svcExtension><extURI>urn:ietf:params:xml:ns:epp:loginSec-1.0</extURI></svcExtension></svcs></login><extension><loginSec:loginSec xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0"><loginSec:userAgent><loginSec:app>app</loginSec:app></loginSec:userAgent></loginSec:loginSec></extension></command></epp>`
+	st.Expect(t, string(x), expected)
+}
+
+func TestEncodeLoginSecNonASCIIPassword(t *testing.T) {
+	// 9 characters, but more than 16 bytes.
+	x, err := encodeLoginUserAgent("user123", "пароль123", "", "1.0", "en", nil, []string{ExtLoginSec}, nil)
+	st.Expect(t, err, nil)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><login><clID>user123</clID><pw>пароль123</pw><options><version>1.0</version><lang>en</lang></options><svcs><svcExtension><extURI>urn:ietf:params:xml:ns:epp:loginSec-1.0</extURI></svcExtension></svcs></login></command></epp>`
+	st.Expect(t, string(x), expected)
+}
+
+func TestEncodeLoginWithoutLoginSec(t *testing.T) {
+	x, err := encodeLoginUserAgent("user123", "this is a long passphrase", "", "1.0", "en", nil, nil, &UserAgent{App: "app"})
+	st.Expect(t, err, nil)
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><login><clID>user123</clID><pw>this is a long passphrase</pw><options><version>1.0</version><lang>en</lang></options><svcs></svcs></login></command></epp>`
+	st.Expect(t, string(x), expected)
+}
+
+func TestScanLoginSecEvents(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <extension>
+      <loginSec:loginSecData xmlns:loginSec="urn:ietf:params:xml:ns:epp:loginSec-1.0">
+        <loginSec:event type="password" level="warning" exDate="2020-03-25T00:00:00Z" lang="en">Password expiring in a week</loginSec:event>
+        <loginSec:event type="stat" name="failedLogins" level="warning" value="100" duration="P1D">Excessive invalid daily logins</loginSec:event>
+      </loginSec:loginSecData>
+    </extension>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54321-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.Result.Code, 1000)
+	st.Expect(t, res.Result.SecurityEvents, []SecurityEvent{
+		{
+			Type:        SecurityEventPassword,
+			Level:       SecurityLevelWarning,
+			ExDate:      time.Date(2020, 3, 25, 0, 0, 0, 0, time.UTC),
+			Lang:        "en",
+			Description: "Password expiring in a week",
+		},
+		{
+			Type:        SecurityEventStat,
+			Name:        "failedLogins",
+			Level:       SecurityLevelWarning,
+			Value:       "100",
+			Duration:    "P1D",
+			Description: "Excessive invalid daily logins",
+		},
+	})
+	st.Expect(t, res.Result.SecurityEvents[0].IsError(), false)
+}
@@ -0,0 +1,161 @@
+package epp
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the default upper bounds, in seconds, of the
+// command latency histogram buckets.
+var DefaultLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics is an Observer that counts commands by server, command, object
+// type and result code, and records their latency in a histogram by server,
+// command and object type. It writes the metrics in the Prometheus text
+// exposition format, and can be served as an http.Handler.
+// Commands for which no valid response was received have code "error".
+//
+// It exports:
+//
+//	epp_commands_total{server, command, object, code}
+//	epp_command_duration_seconds{server, command, object}
+type Metrics struct {
+	buckets []float64
+
+	m         sync.Mutex
+	counts    map[metricLabels]uint64
+	latencies map[metricLabels]*histogram
+}
+
+// metricLabels identifies a metric series. code is empty for latencies.
+type metricLabels struct {
+	server, command, object, code string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns Metrics with the given latency histogram bucket upper
+// bounds in seconds, in increasing order. If none are given,
+// DefaultLatencyBuckets is used.
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	return &Metrics{
+		buckets:   slices.Clone(buckets),
+		counts:    make(map[metricLabels]uint64),
+		latencies: make(map[metricLabels]*histogram),
+	}
+}
+
+// StartCommand implements Observer.
+func (m *Metrics) StartCommand(ctx context.Context, info *CommandInfo) context.Context {
+	return ctx
+}
+
+// FinishCommand implements Observer.
+func (m *Metrics) FinishCommand(ctx context.Context, info *CommandInfo, err error) {
+	l := metricLabels{server: info.Server, command: info.Command, object: info.Object}
+	code := "error"
+	if info.Result != nil {
+		code = strconv.Itoa(info.Result.Code)
+	}
+	seconds := info.Latency.Seconds()
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	h := m.latencies[l]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(m.buckets)+1)}
+		m.latencies[l] = h
+	}
+	i, _ := slices.BinarySearch(m.buckets, seconds)
+	h.counts[i]++
+	h.sum += seconds
+	h.count++
+	l.code = code
+	m.counts[l]++
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	fmt.Fprintln(bw, "# HELP epp_commands_total EPP commands by result code.")
+	fmt.Fprintln(bw, "# TYPE epp_commands_total counter")
+	for _, l := range sortedLabels(m.counts) {
+		fmt.Fprintf(bw, "epp_commands_total{%s} %d\n", l.format(), m.counts[l])
+	}
+
+	fmt.Fprintln(bw, "# HELP epp_command_duration_seconds EPP command latency.")
+	fmt.Fprintln(bw, "# TYPE epp_command_duration_seconds histogram")
+	for _, l := range sortedLabels(m.latencies) {
+		h := m.latencies[l]
+		labels := l.format()
+		var n uint64
+		for i, le := range m.buckets {
+			n += h.counts[i]
+			fmt.Fprintf(bw, "epp_command_duration_seconds_bucket{%s,le=%q} %d\n", labels, strconv.FormatFloat(le, 'g', -1, 64), n)
+		}
+		fmt.Fprintf(bw, "epp_command_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(bw, "epp_command_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(bw, "epp_command_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	err := bw.Flush()
+	return int64(cw.n), err
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// sortedLabels returns the keys of series in a stable order.
+func sortedLabels[V any](series map[metricLabels]V) []metricLabels {
+	labels := make([]metricLabels, 0, len(series))
+	for l := range series {
+		labels = append(labels, l)
+	}
+	slices.SortFunc(labels, func(a, b metricLabels) int {
+		return cmp.Or(
+			cmp.Compare(a.server, b.server),
+			cmp.Compare(a.command, b.command),
+			cmp.Compare(a.object, b.object),
+			cmp.Compare(a.code, b.code),
+		)
+	})
+	return labels
+}
+
+// format returns l as Prometheus labels, without braces.
+func (l metricLabels) format() string {
+	s := `server="` + escapeLabel(l.server) + `",command="` + escapeLabel(l.command) + `",object="` + escapeLabel(l.object) + `"`
+	if l.code != "" {
+		s += `,code="` + l.code + `"`
+	}
+	return s
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a Prometheus label value.
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
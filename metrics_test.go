@@ -0,0 +1,58 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics(0.1, 1)
+	ctx := context.Background()
+	for _, info := range []*CommandInfo{
+		{Server: "reg", Command: "check", Object: "domain", Latency: 50 * time.Millisecond, Result: &Result{Code: 1000}},
+		{Server: "reg", Command: "check", Object: "domain", Latency: 100 * time.Millisecond, Result: &Result{Code: 1000}},
+		{Server: "reg", Command: "check", Object: "domain", Latency: 2 * time.Second, Result: &Result{Code: 2400}},
+		{Server: `a "quoted" server`, Command: "hello", Latency: time.Second},
+	} {
+		ctx := m.StartCommand(ctx, info)
+		var err error
+		if info.Result == nil {
+			err = errors.New("read failed")
+		}
+		m.FinishCommand(ctx, info, err)
+	}
+
+	var b strings.Builder
+	n, err := m.WriteTo(&b)
+	st.Assert(t, err, nil)
+	st.Expect(t, int(n), b.Len())
+	st.Expect(t, b.String(), `# HELP epp_commands_total EPP commands by result code.
+# TYPE epp_commands_total counter
+epp_commands_total{server="a \"quoted\" server",command="hello",object="",code="error"} 1
+epp_commands_total{server="reg",command="check",object="domain",code="1000"} 2
+epp_commands_total{server="reg",command="check",object="domain",code="2400"} 1
+# HELP epp_command_duration_seconds EPP command latency.
+# TYPE epp_command_duration_seconds histogram
+epp_command_duration_seconds_bucket{server="a \"quoted\" server",command="hello",object="",le="0.1"} 0
+epp_command_duration_seconds_bucket{server="a \"quoted\" server",command="hello",object="",le="1"} 1
+epp_command_duration_seconds_bucket{server="a \"quoted\" server",command="hello",object="",le="+Inf"} 1
+epp_command_duration_seconds_sum{server="a \"quoted\" server",command="hello",object=""} 1
+epp_command_duration_seconds_count{server="a \"quoted\" server",command="hello",object=""} 1
+epp_command_duration_seconds_bucket{server="reg",command="check",object="domain",le="0.1"} 2
+epp_command_duration_seconds_bucket{server="reg",command="check",object="domain",le="1"} 2
+epp_command_duration_seconds_bucket{server="reg",command="check",object="domain",le="+Inf"} 3
+epp_command_duration_seconds_sum{server="reg",command="check",object="domain"} 2.15
+epp_command_duration_seconds_count{server="reg",command="check",object="domain"} 3
+`)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	st.Expect(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"), true)
+	st.Expect(t, w.Body.String(), b.String())
+}
@@ -0,0 +1,51 @@
+package epp
+
+import (
+	"context"
+	"time"
+)
+
+// Observer is notified around each EPP command sent on a Conn, such as to
+// record metrics or tracing spans. Its methods may be called concurrently.
+type Observer interface {
+	// StartCommand is called before the request is written. The context it
+	// returns, which must be derived from ctx, is passed to FinishCommand;
+	// it can carry a span started for the command.
+	StartCommand(ctx context.Context, info *CommandInfo) context.Context
+
+	// FinishCommand is called once the response is received or the command
+	// fails. err is set if no valid response was received, in which case
+	// info.Result is nil.
+	FinishCommand(ctx context.Context, info *CommandInfo, err error)
+}
+
+// CommandInfo describes an EPP command passed to an Observer.
+type CommandInfo struct {
+	Command string // command element, e.g. "check", "create" or "hello"
+	Object  string // object type by namespace prefix, e.g. "domain"; empty for login, poll, etc.
+	Server  string // server name from the greeting (<svID>)
+	ClTRID  string // client transaction ID
+
+	Start       time.Time     // when the command was started
+	Latency     time.Duration // time from writing the request to receiving the response
+	RequestSize int           // size of the request frame, including its header
+
+	// The following are set by FinishCommand.
+	ResponseSize int     // size of the response frame, including its header
+	Result       *Result // first result of the response, if received
+}
+
+// newCommandInfo returns the CommandInfo for the request x.
+func (c *Conn) newCommandInfo(x []byte) *CommandInfo {
+	cmd, obj := commandName(x)
+	c.m.Lock()
+	server := c.Greeting.ServerName
+	c.m.Unlock()
+	return &CommandInfo{
+		Command:     cmd,
+		Object:      obj,
+		Server:      server,
+		ClTRID:      requestTransactionID(x),
+		RequestSize: 4 + len(x),
+	}
+}
@@ -0,0 +1,152 @@
+package epp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+type testObserverKey struct{}
+
+// testObserver records the commands it observes.
+type testObserver struct {
+	m        sync.Mutex
+	started  []CommandInfo
+	finished []CommandInfo
+	errs     []error
+}
+
+func (o *testObserver) StartCommand(ctx context.Context, info *CommandInfo) context.Context {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.started = append(o.started, *info)
+	return context.WithValue(ctx, testObserverKey{}, info.ClTRID)
+}
+
+func (o *testObserver) FinishCommand(ctx context.Context, info *CommandInfo, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if ctx.Value(testObserverKey{}) != info.ClTRID {
+		panic("FinishCommand called without the context from StartCommand")
+	}
+	o.finished = append(o.finished, *info)
+	o.errs = append(o.errs, err)
+}
+
+func TestConnObserver(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<domain:check") {
+			return `<epp><response><result code="2400"><msg>Command failed</msg></result><trID><svTRID>SV-1</svTRID></trID></response></epp>`
+		}
+		return testXMLResultOK
+	})
+
+	o := &testObserver{}
+	cfg := testPoolConfig(ls)
+	cfg.Observer = o
+	p := NewPool(cfg)
+	err = p.Do(context.Background(), func(c *Conn) error {
+		_, err := c.CheckDomainContext(WithTransactionID(context.Background(), "ABC-1"), "example.com")
+		return err
+	})
+	st.Expect(t, err != nil, true)
+	p.Close()
+
+	st.Assert(t, len(o.finished), 3)
+	st.Expect(t, len(o.started), 3)
+
+	info := o.finished[1]
+	st.Expect(t, info.Command, "check")
+	st.Expect(t, info.Object, "domain")
+	st.Expect(t, info.Server, "Example EPP server epp.example.com")
+	st.Expect(t, info.ClTRID, "ABC-1")
+	st.Expect(t, info.Start.IsZero(), false)
+	st.Expect(t, info.Latency > 0, true)
+	st.Expect(t, info.RequestSize > 0, true)
+	st.Expect(t, info.ResponseSize > 0, true)
+	st.Assert(t, info.Result != nil, true)
+	st.Expect(t, info.Result.Code, 2400)
+	st.Expect(t, info.Result.SvTRID, "SV-1")
+	st.Expect(t, o.errs[1], nil)
+
+	st.Expect(t, o.started[1].Result == nil, true)
+	st.Expect(t, o.finished[0].Command, "login")
+	st.Expect(t, o.finished[2].Command, "logout")
+}
+
+// span stands in for a tracing span, such as an OpenTelemetry trace.Span.
+type span struct {
+	name  string
+	attrs []string
+	err   error
+}
+
+func (s *span) SetAttribute(key string, value any) {
+	s.attrs = append(s.attrs, fmt.Sprintf("%s=%v", key, value))
+}
+
+func (s *span) End() {
+	fmt.Printf("%s %s", s.name, strings.Join(s.attrs, " "))
+	if s.err != nil {
+		fmt.Printf(" error=%q", s.err)
+	}
+	fmt.Println()
+}
+
+type spanKey struct{}
+
+// tracingObserver is an Observer that records a span for each command.
+// With OpenTelemetry, StartCommand would call tracer.Start and
+// FinishCommand would use trace.SpanFromContext.
+type tracingObserver struct{}
+
+func (tracingObserver) StartCommand(ctx context.Context, info *CommandInfo) context.Context {
+	s := &span{name: "epp." + info.Command}
+	s.SetAttribute("epp.command", info.Command)
+	if info.Object != "" {
+		s.SetAttribute("epp.object", info.Object)
+	}
+	s.SetAttribute("server.name", info.Server)
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+func (tracingObserver) FinishCommand(ctx context.Context, info *CommandInfo, err error) {
+	s := ctx.Value(spanKey{}).(*span)
+	if info.Result != nil {
+		s.SetAttribute("epp.result_code", info.Result.Code)
+	}
+	s.err = err
+	s.End()
+}
+
+func ExampleObserver() {
+	ls, err := newLocalServer()
+	if err != nil {
+		panic(err)
+	}
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		return testXMLResultOK
+	})
+
+	cfg := testPoolConfig(ls)
+	cfg.Observer = tracingObserver{}
+	p := NewPool(cfg)
+	p.Do(context.Background(), func(c *Conn) error {
+		_, err := c.CheckDomain("example.com")
+		return err
+	})
+	p.Close()
+
+	// Output:
+	// epp.login epp.command=login server.name=Example EPP server epp.example.com epp.result_code=1000
+	// epp.check epp.command=check epp.object=domain server.name=Example EPP server epp.example.com epp.result_code=1000
+	// epp.logout epp.command=logout server.name=Example EPP server epp.example.com epp.result_code=1500
+}
@@ -2,7 +2,8 @@ package epp
 
 import (
 	"bytes"
-	"fmt"
+	"context"
+	"encoding/xml"
 	"time"
 
 	"github.com/nbio/xx"
@@ -10,16 +11,16 @@ import (
 
 // PollReq requests a message from the server's message queue.
 func (c *Conn) PollReq() (*PollResponse, error) {
+	return c.PollReqContext(context.Background())
+}
+
+// PollReqContext is like PollReq, but honors cancellation and deadlines from ctx.
+func (c *Conn) PollReqContext(ctx context.Context) (*PollResponse, error) {
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<poll op="req"/>`)
 	buf.WriteString(xmlCommandSuffix)
 
-	err := c.writeRequest(buf.Bytes())
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := c.readResponse()
+	res, err := c.request(ctx, buf.Bytes())
 	if err != nil {
 		return nil, err
 	}
@@ -29,16 +30,18 @@ func (c *Conn) PollReq() (*PollResponse, error) {
 
 // PollAck acknowledges a message from the server's message queue.
 func (c *Conn) PollAck(msgID string) (*PollResponse, error) {
+	return c.PollAckContext(context.Background(), msgID)
+}
+
+// PollAckContext is like PollAck, but honors cancellation and deadlines from ctx.
+func (c *Conn) PollAckContext(ctx context.Context, msgID string) (*PollResponse, error) {
 	buf := bytes.NewBufferString(xmlCommandPrefix)
-	fmt.Fprintf(buf, `<poll op="ack" msgID="%s"/>`, msgID)
+	buf.WriteString(`<poll op="ack" msgID="`)
+	xml.EscapeText(buf, []byte(msgID))
+	buf.WriteString(`"/>`)
 	buf.WriteString(xmlCommandSuffix)
 
-	err := c.writeRequest(buf.Bytes())
-	if err != nil {
-		return nil, err
-	}
-
-	res, err := c.readResponse()
+	res, err := c.request(ctx, buf.Bytes())
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +55,127 @@ type PollResponse struct {
 	ID      string
 	Date    time.Time
 	Message string
+
+	// Data holds the typed content of a message returned by PollReq,
+	// or nil if the message has none that is recognized.
+	Data PollMessage
+
+	TransactionID
+}
+
+// PollMessage is the typed content of a poll message. Its concrete type is
+// one of *PollTransfer, *PollPendingAction, *PollLowBalance or *PollChange.
+type PollMessage interface {
+	pollMessage()
+}
+
+// PollTransfer is a transfer notification, sent to the gaining and losing
+// clients when a transfer is requested or acted on.
+// https://tools.ietf.org/html/rfc5731#section-3.2.4
+type PollTransfer struct {
+	Object string    // ObjDomain or ObjContact
+	ID     string    // <domain:name> or <contact:id>
+	Status string    // <trStatus>
+	REID   string    // <reID>
+	REDate time.Time // <reDate>
+	ACID   string    // <acID>
+	ACDate time.Time // <acDate>
+	ExDate time.Time // <domain:exDate>
+}
+
+// PollPendingAction is a notification that a command which returned
+// 1001 (action pending) has been completed by the server.
+// https://tools.ietf.org/html/rfc5731#section-3.3
+type PollPendingAction struct {
+	Object string    // ObjDomain or ObjContact
+	ID     string    // <domain:name> or <contact:id>
+	Result bool      // paResult attribute: true if the action succeeded
+	ClTRID string    // <paTRID><clTRID> of the original command
+	SvTRID string    // <paTRID><svTRID> of the original command
+	Date   time.Time // <paDate>
+}
+
+// PollLowBalance is a notification that the account balance has fallen
+// below its threshold.
+// https://www.verisign.com/assets/epp-sdk/verisign_epp-extension_low-balance_v01.html
+type PollLowBalance struct {
+	RegistrarName   string // <lowbalance-poll:registrarName>
+	CreditLimit     string // <lowbalance-poll:creditLimit>
+	CreditThreshold string // <lowbalance-poll:creditThreshold>
+	ThresholdType   string // type attribute of <creditThreshold>, FIXED or PERCENT
+	AvailableCredit string // <lowbalance-poll:availableCredit>
+}
+
+// PollChange is a change poll record: a notification that the server or
+// a third party changed an object sponsored by the client.
+// https://tools.ietf.org/html/rfc8590
+type PollChange struct {
+	State         string    // state attribute: "before" or "after" (default) the change
+	Operation     string    // <changePoll:operation>, e.g. create, update or custom
+	OperationName string    // op attribute of <changePoll:operation>, for custom operations
+	Date          time.Time // <changePoll:date>
+	SvTRID        string    // <changePoll:svTRID>
+	Who           string    // <changePoll:who>
+	CaseID        string    // <changePoll:caseId>
+	CaseType      string    // type attribute of <changePoll:caseId>, e.g. udrp or urs
+	Reason        string    // <changePoll:reason>
+	ReasonLang    string    // lang attribute of <changePoll:reason>
+
+	// The object in State, from the info data in the response.
+	// At most one is set.
+	Domain  *DomainInfoResponse
+	Contact *ContactInfoResponse
+	Host    *HostInfoResponse
+}
+
+func (*PollTransfer) pollMessage()      {}
+func (*PollPendingAction) pollMessage() {}
+func (*PollLowBalance) pollMessage()    {}
+func (*PollChange) pollMessage()        {}
+
+// setPollData completes r.PollResponse.Data from the response data
+// scanned into r. Transfer and info data is only taken as a poll message
+// if r holds a poll message, identified by its <qDate>.
+func (r *Response) setPollData() {
+	pr := &r.PollResponse
+	if pr.Date.IsZero() {
+		pr.Data = nil
+		return
+	}
+	switch d := pr.Data.(type) {
+	case *PollChange:
+		switch {
+		case r.DomainInfoResponse.Domain != "":
+			d.Domain = &r.DomainInfoResponse
+		case r.ContactInfoResponse.ID != "":
+			d.Contact = &r.ContactInfoResponse
+		case r.HostInfoResponse.Host != "":
+			d.Host = &r.HostInfoResponse
+		}
+	case nil:
+		if dtr := &r.DomainTransferResponse; dtr.Domain != "" {
+			pr.Data = &PollTransfer{
+				Object: ObjDomain,
+				ID:     dtr.Domain,
+				Status: dtr.Status,
+				REID:   dtr.REID,
+				REDate: dtr.REDate,
+				ACID:   dtr.ACID,
+				ACDate: dtr.ACDate,
+				ExDate: dtr.ExDate,
+			}
+		} else if ctr := &r.ContactTransferResponse; ctr.ID != "" {
+			pr.Data = &PollTransfer{
+				Object: ObjContact,
+				ID:     ctr.ID,
+				Status: ctr.Status,
+				REID:   ctr.REID,
+				REDate: ctr.REDate,
+				ACID:   ctr.ACID,
+				ACDate: ctr.ACDate,
+			}
+		}
+	}
 }
 
 func init() {
@@ -73,4 +197,122 @@ func init() {
 		c.Value.(*Response).PollResponse.Message = string(c.CharData)
 		return nil
 	})
+
+	// Pending action notifications
+	for _, obj := range []struct{ urn, id string }{{ObjDomain, "name"}, {ObjContact, "id"}} {
+		path = "epp > response > resData > " + obj.urn + " panData"
+		urn := obj.urn
+		scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+			c.Value.(*Response).PollResponse.Data = &PollPendingAction{Object: urn}
+			return nil
+		})
+		scanResponse.MustHandleStartElement(path+">"+obj.id, func(c *xx.Context) error {
+			pollPendingAction(c).Result = c.AttrBool("", "paResult")
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">"+obj.id, func(c *xx.Context) error {
+			pollPendingAction(c).ID = string(c.CharData)
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paTRID>clTRID", func(c *xx.Context) error {
+			pollPendingAction(c).ClTRID = string(c.CharData)
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paTRID>svTRID", func(c *xx.Context) error {
+			pollPendingAction(c).SvTRID = string(c.CharData)
+			return nil
+		})
+		scanResponse.MustHandleCharData(path+">paDate", func(c *xx.Context) error {
+			var err error
+			pollPendingAction(c).Date, err = time.Parse(time.RFC3339, string(c.CharData))
+			return err
+		})
+	}
+
+	// Low balance notifications
+	path = "epp > response > resData > " + ExtLowBalance + " pollData"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		c.Value.(*Response).PollResponse.Data = &PollLowBalance{}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">registrarName", func(c *xx.Context) error {
+		pollLowBalance(c).RegistrarName = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">creditLimit", func(c *xx.Context) error {
+		pollLowBalance(c).CreditLimit = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">creditThreshold", func(c *xx.Context) error {
+		pollLowBalance(c).ThresholdType = c.Attr("", "type")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">creditThreshold", func(c *xx.Context) error {
+		pollLowBalance(c).CreditThreshold = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">availableCredit", func(c *xx.Context) error {
+		pollLowBalance(c).AvailableCredit = string(c.CharData)
+		return nil
+	})
+
+	// Change poll records
+	path = "epp > response > extension > " + ExtChangePoll + " changeData"
+	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
+		state := c.Attr("", "state")
+		if state == "" {
+			state = "after"
+		}
+		c.Value.(*Response).PollResponse.Data = &PollChange{State: state}
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">operation", func(c *xx.Context) error {
+		pollChange(c).OperationName = c.Attr("", "op")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">operation", func(c *xx.Context) error {
+		pollChange(c).Operation = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">date", func(c *xx.Context) error {
+		var err error
+		pollChange(c).Date, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">svTRID", func(c *xx.Context) error {
+		pollChange(c).SvTRID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">who", func(c *xx.Context) error {
+		pollChange(c).Who = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">caseId", func(c *xx.Context) error {
+		pollChange(c).CaseType = c.Attr("", "type")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">caseId", func(c *xx.Context) error {
+		pollChange(c).CaseID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">reason", func(c *xx.Context) error {
+		pollChange(c).ReasonLang = c.Attr("", "lang")
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reason", func(c *xx.Context) error {
+		pollChange(c).Reason = string(c.CharData)
+		return nil
+	})
+}
+
+func pollPendingAction(c *xx.Context) *PollPendingAction {
+	return c.Value.(*Response).PollResponse.Data.(*PollPendingAction)
+}
+
+func pollLowBalance(c *xx.Context) *PollLowBalance {
+	return c.Value.(*Response).PollResponse.Data.(*PollLowBalance)
+}
+
+func pollChange(c *xx.Context) *PollChange {
+	return c.Value.(*Response).PollResponse.Data.(*PollChange)
 }
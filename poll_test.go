@@ -1,7 +1,10 @@
 package epp
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -15,3 +18,222 @@ import (
 func TestPollDummy(t *testing.T) {
 	st.Expect(t, true, true)
 }
+
+func TestPollAckEscapesMsgID(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	reqs := make(chan string, 1)
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<poll") {
+			reqs <- req
+		}
+		return testXMLResultOK
+	})
+
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+	err = p.Do(context.Background(), func(c *Conn) error {
+		_, err := c.PollAck(`1"/><x a="`)
+		return err
+	})
+	st.Expect(t, err, nil)
+	st.Expect(t, strings.Contains(<-reqs, `<poll op="ack" msgID="1&#34;/&gt;&lt;x a=&#34;"/>`), true)
+}
+
+func TestPollTransfer(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1301">
+      <msg>Command completed successfully; ack to dequeue</msg>
+    </result>
+    <msgQ count="5" id="12345">
+      <qDate>2000-06-08T22:00:00.0Z</qDate>
+      <msg>Transfer requested.</msg>
+    </msgQ>
+    <resData>
+      <domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:trStatus>pending</domain:trStatus>
+        <domain:reID>ClientX</domain:reID>
+        <domain:reDate>2000-06-08T22:00:00.0Z</domain:reDate>
+        <domain:acID>ClientY</domain:acID>
+        <domain:acDate>2000-06-13T22:00:00.0Z</domain:acDate>
+        <domain:exDate>2002-09-08T22:00:00.0Z</domain:exDate>
+      </domain:trnData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	res, err := parseResponse([]byte(x))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.PollResponse.ID, "12345")
+	st.Expect(t, res.PollResponse.Data, PollMessage(&PollTransfer{
+		Object: ObjDomain,
+		ID:     "example.com",
+		Status: "pending",
+		REID:   "ClientX",
+		REDate: time.Date(2000, 6, 8, 22, 0, 0, 0, time.UTC),
+		ACID:   "ClientY",
+		ACDate: time.Date(2000, 6, 13, 22, 0, 0, 0, time.UTC),
+		ExDate: time.Date(2002, 9, 8, 22, 0, 0, 0, time.UTC),
+	}))
+}
+
+func TestPollDataOnlyForPollMessages(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1001">
+      <msg>Command completed successfully; action pending</msg>
+    </result>
+    <msgQ count="5" id="12345"/>
+    <resData>
+      <domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.com</domain:name>
+        <domain:trStatus>pending</domain:trStatus>
+      </domain:trnData>
+    </resData>
+  </response>
+</epp>`
+
+	res, err := parseResponse([]byte(x))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainTransferResponse.Domain, "example.com")
+	st.Expect(t, res.PollResponse.Data, nil)
+}
+
+func TestPollPendingAction(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1301">
+      <msg>Command completed successfully; ack to dequeue</msg>
+    </result>
+    <msgQ count="1" id="201">
+      <qDate>1999-04-04T22:01:00.0Z</qDate>
+      <msg>Pending action completed successfully.</msg>
+    </msgQ>
+    <resData>
+      <contact:panData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+        <contact:id paResult="1">sh8013</contact:id>
+        <contact:paTRID>
+          <clTRID>ABC-12345</clTRID>
+          <svTRID>54321-XYZ</svTRID>
+        </contact:paTRID>
+        <contact:paDate>1999-04-04T22:00:00.0Z</contact:paDate>
+      </contact:panData>
+    </resData>
+    <trID>
+      <clTRID>BCD-23456</clTRID>
+      <svTRID>65432-WXY</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	res, err := parseResponse([]byte(x))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.PollResponse.Data, PollMessage(&PollPendingAction{
+		Object: ObjContact,
+		ID:     "sh8013",
+		Result: true,
+		ClTRID: "ABC-12345",
+		SvTRID: "54321-XYZ",
+		Date:   time.Date(1999, 4, 4, 22, 0, 0, 0, time.UTC),
+	}))
+	st.Expect(t, res.PollResponse.ClTRID, "BCD-23456")
+}
+
+func TestPollLowBalance(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1301">
+      <msg>Command completed successfully; ack to dequeue</msg>
+    </result>
+    <msgQ count="1" id="12345">
+      <qDate>2013-03-25T18:20:59.0Z</qDate>
+      <msg>Low Account Balance</msg>
+    </msgQ>
+    <resData>
+      <lowbalance-poll:pollData xmlns:lowbalance-poll="http://www.verisign.com/epp/lowbalance-poll-1.0">
+        <lowbalance-poll:registrarName>Test Registrar</lowbalance-poll:registrarName>
+        <lowbalance-poll:creditLimit>1000</lowbalance-poll:creditLimit>
+        <lowbalance-poll:creditThreshold type="PERCENT">10</lowbalance-poll:creditThreshold>
+        <lowbalance-poll:availableCredit>80</lowbalance-poll:availableCredit>
+      </lowbalance-poll:pollData>
+    </resData>
+  </response>
+</epp>`
+
+	res, err := parseResponse([]byte(x))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.PollResponse.Data, PollMessage(&PollLowBalance{
+		RegistrarName:   "Test Registrar",
+		CreditLimit:     "1000",
+		CreditThreshold: "10",
+		ThresholdType:   "PERCENT",
+		AvailableCredit: "80",
+	}))
+}
+
+func TestPollChange(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1301">
+      <msg>Command completed successfully; ack to dequeue</msg>
+    </result>
+    <msgQ count="201" id="1">
+      <qDate>2013-10-22T14:25:57.0Z</qDate>
+      <msg>Registry initiated update of domain.</msg>
+    </msgQ>
+    <resData>
+      <domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>domain.example</domain:name>
+        <domain:roid>EXAMPLE1-REP</domain:roid>
+        <domain:status s="serverUpdateProhibited"/>
+        <domain:clID>ClientX</domain:clID>
+      </domain:infData>
+    </resData>
+    <extension>
+      <changePoll:changeData xmlns:changePoll="urn:ietf:params:xml:ns:changePoll-1.0" state="before">
+        <changePoll:operation op="purge">delete</changePoll:operation>
+        <changePoll:date>2013-10-22T14:25:57.0Z</changePoll:date>
+        <changePoll:svTRID>12345-XYZ</changePoll:svTRID>
+        <changePoll:who>URS Admin</changePoll:who>
+        <changePoll:caseId type="urs">urs123</changePoll:caseId>
+        <changePoll:reason lang="en">URS Lock</changePoll:reason>
+      </changePoll:changeData>
+    </extension>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54321-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	res, err := parseResponse([]byte(x))
+	st.Expect(t, err, nil)
+	pc, ok := res.PollResponse.Data.(*PollChange)
+	st.Assert(t, ok, true)
+	st.Expect(t, pc.State, "before")
+	st.Expect(t, pc.Operation, "delete")
+	st.Expect(t, pc.OperationName, "purge")
+	st.Expect(t, pc.Date, time.Date(2013, 10, 22, 14, 25, 57, 0, time.UTC))
+	st.Expect(t, pc.SvTRID, "12345-XYZ")
+	st.Expect(t, pc.Who, "URS Admin")
+	st.Expect(t, pc.CaseID, "urs123")
+	st.Expect(t, pc.CaseType, "urs")
+	st.Expect(t, pc.Reason, "URS Lock")
+	st.Expect(t, pc.ReasonLang, "en")
+	st.Assert(t, pc.Domain != nil, true)
+	st.Expect(t, pc.Domain.Domain, "domain.example")
+	st.Expect(t, pc.Domain.Status, []string{"serverUpdateProhibited"})
+	st.Expect(t, pc.Contact == nil && pc.Host == nil, true)
+}
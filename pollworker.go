@@ -0,0 +1,340 @@
+package epp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// PollWorkerConfig configures a PollWorker.
+type PollWorkerConfig struct {
+	// Do runs f with a logged-in session, such as Pool.Do. It must be set.
+	Do func(ctx context.Context, f func(c *Conn) error) error
+
+	// Handlers for each type of poll message, selected by the type of
+	// PollResponse.Data. A nil error acknowledges the message; otherwise
+	// the message is left in the queue and handled again after a backoff.
+	Transfer      func(ctx context.Context, pr *PollResponse, m *PollTransfer) error
+	PendingAction func(ctx context.Context, pr *PollResponse, m *PollPendingAction) error
+	LowBalance    func(ctx context.Context, pr *PollResponse, m *PollLowBalance) error
+	Change        func(ctx context.Context, pr *PollResponse, m *PollChange) error
+
+	// Other handles messages with no typed content, or whose type has no
+	// handler. If nil, such messages are acknowledged without handling.
+	Other func(ctx context.Context, pr *PollResponse) error
+
+	// Journal records messages that were handled but not yet acknowledged,
+	// so a message is not handled twice if the worker stops before its ack.
+	// If nil, an in-memory journal is used, which only covers failed acks.
+	Journal PollJournal
+
+	// MinBackoff is how long the worker waits after finding the queue empty
+	// or after an error. It doubles with each consecutive wait, up to
+	// MaxBackoff. Defaults are 10 seconds and 5 minutes.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Error, if set, is called with each error that causes the worker to
+	// back off.
+	Error func(err error)
+}
+
+// PollWorker drains the server message queue, passing each message to
+// its handler and acknowledging it only after the handler succeeds.
+// Messages are delivered at least once.
+type PollWorker struct {
+	cfg PollWorkerConfig
+}
+
+// NewPollWorker returns a PollWorker configured by cfg.
+func NewPollWorker(cfg PollWorkerConfig) *PollWorker {
+	if cfg.Journal == nil {
+		cfg.Journal = newMemoryJournal()
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 10 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = 5 * time.Minute
+		if cfg.MaxBackoff < cfg.MinBackoff {
+			cfg.MaxBackoff = cfg.MinBackoff
+		}
+	}
+	return &PollWorker{cfg: cfg}
+}
+
+// Run drains the message queue until ctx is done, backing off while the
+// queue is empty or after an error. It returns ctx.Err().
+func (w *PollWorker) Run(ctx context.Context) error {
+	backoff := w.cfg.MinBackoff
+	for {
+		n, err := w.Drain(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && w.cfg.Error != nil {
+			w.cfg.Error(err)
+		}
+		if n > 0 && err == nil {
+			backoff = w.cfg.MinBackoff
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		backoff = min(backoff*2, w.cfg.MaxBackoff)
+	}
+}
+
+// Drain handles and acknowledges messages until the queue is empty,
+// returning the number of messages acknowledged. It stops at the first error.
+func (w *PollWorker) Drain(ctx context.Context) (int, error) {
+	for n := 0; ; n++ {
+		ok, err := w.Next(ctx)
+		if err != nil || !ok {
+			return n, err
+		}
+	}
+}
+
+// Next handles and acknowledges the next message in the queue.
+// It returns false if the queue is empty.
+func (w *PollWorker) Next(ctx context.Context) (bool, error) {
+	var pr *PollResponse
+	err := w.cfg.Do(ctx, func(c *Conn) error {
+		var err error
+		pr, err = c.PollReqContext(ctx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if pr.ID == "" {
+		return false, nil
+	}
+
+	handled, err := w.cfg.Journal.Handled(pr.ID)
+	if err != nil {
+		return false, err
+	}
+	if !handled {
+		err = w.handle(ctx, pr)
+		if err != nil {
+			return false, fmt.Errorf("epp: poll message %s: %w", pr.ID, err)
+		}
+		err = w.cfg.Journal.MarkHandled(pr.ID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = w.cfg.Do(ctx, func(c *Conn) error {
+		_, err := c.PollAckContext(ctx, pr.ID)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, w.cfg.Journal.MarkAcked(pr.ID)
+}
+
+// handle passes pr to the handler for its type.
+func (w *PollWorker) handle(ctx context.Context, pr *PollResponse) error {
+	switch m := pr.Data.(type) {
+	case *PollTransfer:
+		if w.cfg.Transfer != nil {
+			return w.cfg.Transfer(ctx, pr, m)
+		}
+	case *PollPendingAction:
+		if w.cfg.PendingAction != nil {
+			return w.cfg.PendingAction(ctx, pr, m)
+		}
+	case *PollLowBalance:
+		if w.cfg.LowBalance != nil {
+			return w.cfg.LowBalance(ctx, pr, m)
+		}
+	case *PollChange:
+		if w.cfg.Change != nil {
+			return w.cfg.Change(ctx, pr, m)
+		}
+	}
+	if w.cfg.Other != nil {
+		return w.cfg.Other(ctx, pr)
+	}
+	return nil
+}
+
+// PollJournal records the IDs of poll messages that were handled but not
+// yet acknowledged. Implementations must be safe for concurrent use.
+type PollJournal interface {
+	// Handled reports whether message id was handled and not acknowledged.
+	Handled(id string) (bool, error)
+
+	// MarkHandled records that message id was handled.
+	MarkHandled(id string) error
+
+	// MarkAcked records that message id was acknowledged,
+	// after which it can be forgotten.
+	MarkAcked(id string) error
+}
+
+// memoryJournal is a PollJournal held in memory.
+type memoryJournal struct {
+	m   sync.Mutex
+	ids map[string]bool
+}
+
+func newMemoryJournal() *memoryJournal {
+	return &memoryJournal{ids: make(map[string]bool)}
+}
+
+func (j *memoryJournal) Handled(id string) (bool, error) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	return j.ids[id], nil
+}
+
+func (j *memoryJournal) MarkHandled(id string) error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.ids[id] = true
+	return nil
+}
+
+func (j *memoryJournal) MarkAcked(id string) error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	delete(j.ids, id)
+	return nil
+}
+
+// FileJournal is a PollJournal kept in a local file, so that it survives
+// a restart. Each change is appended to the file and synced to disk.
+type FileJournal struct {
+	mem  *memoryJournal
+	m    sync.Mutex
+	file *os.File
+}
+
+// OpenFileJournal opens the journal at path, creating it if necessary.
+// The file is compacted to the messages still awaiting acknowledgement.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	mem := newMemoryJournal()
+	f, err := os.Open(path)
+	switch {
+	case err == nil:
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			op, id, ok := strings.Cut(s.Text(), " ")
+			if !ok {
+				continue
+			}
+			switch op {
+			case "handled":
+				mem.ids[id] = true
+			case "acked":
+				delete(mem.ids, id)
+			}
+		}
+		err = s.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	// Rewrite the pending entries, then append to the compacted file.
+	tmp := path + ".tmp"
+	f, err = os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	for id := range mem.ids {
+		fmt.Fprintf(f, "handled %s\n", id)
+	}
+	err = f.Sync()
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	f, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileJournal{mem: mem, file: f}, nil
+}
+
+// Handled implements PollJournal. It returns an error for an id that
+// cannot be recorded, so the message is not handled.
+func (j *FileJournal) Handled(id string) (bool, error) {
+	if err := validateJournalID(id); err != nil {
+		return false, err
+	}
+	return j.mem.Handled(id)
+}
+
+// MarkHandled implements PollJournal.
+// It returns an error if id is empty or contains white space,
+// which would corrupt the journal file.
+func (j *FileJournal) MarkHandled(id string) error {
+	if err := validateJournalID(id); err != nil {
+		return err
+	}
+	if err := j.append("handled", id); err != nil {
+		return err
+	}
+	return j.mem.MarkHandled(id)
+}
+
+// MarkAcked implements PollJournal.
+func (j *FileJournal) MarkAcked(id string) error {
+	if err := validateJournalID(id); err != nil {
+		return err
+	}
+	if err := j.append("acked", id); err != nil {
+		return err
+	}
+	return j.mem.MarkAcked(id)
+}
+
+// validateJournalID returns an error if id cannot be written as a field
+// of a line in the journal file.
+func validateJournalID(id string) error {
+	if id == "" || strings.ContainsFunc(id, unicode.IsSpace) {
+		return fmt.Errorf("epp: poll message ID %q cannot be journaled", id)
+	}
+	return nil
+}
+
+func (j *FileJournal) append(op, id string) error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	_, err := fmt.Fprintf(j.file, "%s %s\n", op, id)
+	if err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file.
+func (j *FileJournal) Close() error {
+	return j.file.Close()
+}
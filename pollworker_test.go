@@ -0,0 +1,148 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+// testPollQueue serves a message queue of transfer notifications.
+type testPollQueue struct {
+	m       sync.Mutex
+	ids     []string
+	acks    []string
+	failAck bool
+}
+
+func (q *testPollQueue) respond(req string) string {
+	q.m.Lock()
+	defer q.m.Unlock()
+	switch {
+	case strings.Contains(req, `<poll op="req"/>`):
+		if len(q.ids) == 0 {
+			return `<epp><response><result code="1300"><msg>Command completed successfully; no messages</msg></result></response></epp>`
+		}
+		return fmt.Sprintf(`<epp><response><result code="1301"><msg>Command completed successfully; ack to dequeue</msg></result>`+
+			`<msgQ count="%d" id="%s"><qDate>2000-06-08T22:00:00.0Z</qDate><msg>Transfer requested.</msg></msgQ>`+
+			`<resData><domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>%s.example</domain:name><domain:trStatus>pending</domain:trStatus></domain:trnData></resData>`+
+			`</response></epp>`, len(q.ids), q.ids[0], q.ids[0])
+	case strings.Contains(req, `<poll op="ack"`):
+		if q.failAck {
+			q.failAck = false
+			return `<epp><response><result code="2400"><msg>Command failed</msg></result></response></epp>`
+		}
+		q.acks = append(q.acks, q.ids[0])
+		q.ids = q.ids[1:]
+	}
+	return testXMLResultOK
+}
+
+func TestPollWorkerDrain(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	q := &testPollQueue{ids: []string{"1", "2", "3"}}
+	serveTestSessions(ls, q.respond)
+
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+
+	var handled []string
+	fail := true
+	w := NewPollWorker(PollWorkerConfig{
+		Do: p.Do,
+		Transfer: func(ctx context.Context, pr *PollResponse, m *PollTransfer) error {
+			if m.ID == "2.example" && fail {
+				fail = false
+				return errors.New("handler failed")
+			}
+			handled = append(handled, m.ID)
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+	n, err := w.Drain(ctx)
+	st.Expect(t, n, 1)
+	st.Expect(t, err != nil && strings.Contains(err.Error(), "handler failed"), true)
+	st.Expect(t, q.acks, []string{"1"})
+
+	// An ack that fails must not cause the message to be handled again.
+	q.failAck = true
+	n, err = w.Drain(ctx)
+	st.Expect(t, n, 0)
+	st.Expect(t, errors.Is(err, ErrCommandFailed), true)
+
+	n, err = w.Drain(ctx)
+	st.Expect(t, n, 2)
+	st.Expect(t, err, nil)
+	st.Expect(t, handled, []string{"1.example", "2.example", "3.example"})
+	st.Expect(t, q.acks, []string{"1", "2", "3"})
+}
+
+func TestPollWorkerRun(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	q := &testPollQueue{ids: []string{"1"}}
+	serveTestSessions(ls, q.respond)
+
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+
+	done := make(chan string, 2)
+	w := NewPollWorker(PollWorkerConfig{
+		Do: p.Do,
+		Other: func(ctx context.Context, pr *PollResponse) error {
+			done <- pr.ID
+			return nil
+		},
+		MinBackoff: 5 * time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- w.Run(ctx) }()
+
+	st.Expect(t, <-done, "1")
+	q.m.Lock()
+	q.ids = append(q.ids, "2")
+	q.m.Unlock()
+	st.Expect(t, <-done, "2")
+	cancel()
+	st.Expect(t, <-errc, context.Canceled)
+}
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.journal")
+	j, err := OpenFileJournal(path)
+	st.Assert(t, err, nil)
+	st.Expect(t, j.MarkHandled("1"), nil)
+	st.Expect(t, j.MarkHandled("2"), nil)
+	st.Expect(t, j.MarkAcked("1"), nil)
+	// IDs that would break the line format are rejected.
+	st.Reject(t, j.MarkHandled("3\nacked 2"), nil)
+	st.Reject(t, j.MarkHandled("3 4"), nil)
+	st.Reject(t, j.MarkHandled(""), nil)
+	_, err = j.Handled("3\n")
+	st.Reject(t, err, nil)
+	st.Expect(t, j.Close(), nil)
+
+	j, err = OpenFileJournal(path)
+	st.Assert(t, err, nil)
+	defer j.Close()
+	ok, err := j.Handled("1")
+	st.Expect(t, err, nil)
+	st.Expect(t, ok, false)
+	ok, err = j.Handled("2")
+	st.Expect(t, err, nil)
+	st.Expect(t, ok, true)
+}
@@ -0,0 +1,328 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by Pool.Get after the pool has been closed.
+var ErrPoolClosed = errors.New("epp: pool closed")
+
+// PoolConfig configures a Pool of logged-in EPP sessions for a single
+// registry profile.
+type PoolConfig struct {
+	// Dial opens a new network connection (usually TLS) to the EPP server.
+	// It must be set.
+	Dial func(ctx context.Context) (net.Conn, error)
+
+	// User, Password and NewPassword are the credentials sent in <login>.
+	User        string
+	Password    string
+	NewPassword string
+
+	// UserAgent is copied to Conn.UserAgent for each session.
+	UserAgent *UserAgent
+
+	// SecurityEvents, if set, is called with the security events returned
+	// by each login (RFC 8807), such as an upcoming password expiry.
+	// It is also called if the login fails.
+	SecurityEvents func(events []SecurityEvent)
+
+	// Logger is copied to Conn.Logger for each session.
+	Logger *slog.Logger
+
+	// Observer is copied to Conn.Observer for each session.
+	Observer Observer
+
+	// RateLimiter is copied to Conn.RateLimiter for each session, so its
+	// limits apply to the pool as a whole. To limit a registry profile used
+	// by several pools, share one RateLimiter between them.
+	RateLimiter *RateLimiter
+
+	// Timeout is copied to Conn.Timeout for each session.
+	Timeout time.Duration
+
+	// MaxSessions is the maximum number of concurrent sessions,
+	// typically the limit imposed by the registry. Defaults to 1.
+	MaxSessions int
+
+	// KeepAlive is the interval at which idle sessions are sent a <hello>
+	// to keep them open and verify they are healthy. Zero disables keepalives.
+	KeepAlive time.Duration
+
+	// SessionLimitBackoff is how long a session slot is held back after
+	// the server refuses a login because its session limit is exceeded (2502).
+	// Defaults to one minute.
+	SessionLimitBackoff time.Duration
+
+	// Retry configures DoRetry and DoTransform.
+	Retry RetryPolicy
+
+	// Setup, if set, is called on each new session after login.
+	// A non-nil error discards the session.
+	Setup func(ctx context.Context, c *Conn) error
+}
+
+// Pool maintains a set of logged-in EPP sessions, handing them out to
+// callers and replacing sessions that fail. It is safe for concurrent use.
+type Pool struct {
+	cfg PoolConfig
+
+	// slots holds a token for each open session, or slot held back.
+	slots chan struct{}
+
+	// idle holds sessions available for use.
+	idle chan idleConn
+
+	done      chan struct{}
+	closeOnce sync.Once
+	mClose    sync.Mutex // serializes closing done with Put
+	wg        sync.WaitGroup
+}
+
+type idleConn struct {
+	c    *Conn
+	used time.Time
+}
+
+// NewPool returns a Pool configured by cfg. Sessions are opened on demand
+// by Get, up to cfg.MaxSessions.
+func NewPool(cfg PoolConfig) *Pool {
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = 1
+	}
+	if cfg.SessionLimitBackoff <= 0 {
+		cfg.SessionLimitBackoff = time.Minute
+	}
+	p := &Pool{
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.MaxSessions),
+		idle:  make(chan idleConn, cfg.MaxSessions),
+		done:  make(chan struct{}),
+	}
+	if cfg.KeepAlive > 0 {
+		p.wg.Add(1)
+		go p.keepAlive()
+	}
+	return p
+}
+
+// Get returns a logged-in session from p, opening a new one if none are
+// idle and p has fewer than MaxSessions open. Otherwise it blocks until a
+// session is returned with Put or ctx is done.
+// The caller must return the session with Put.
+func (p *Pool) Get(ctx context.Context) (*Conn, error) {
+	for {
+		select {
+		case <-p.done:
+			return nil, ErrPoolClosed
+		default:
+		}
+
+		// Prefer an idle session over opening a new one.
+		select {
+		case ic := <-p.idle:
+			if ic.c.broken.Load() {
+				p.discard(ic.c)
+				continue
+			}
+			return ic.c, nil
+		default:
+		}
+
+		select {
+		case ic := <-p.idle:
+			if ic.c.broken.Load() {
+				p.discard(ic.c)
+				continue
+			}
+			return ic.c, nil
+		case p.slots <- struct{}{}:
+			c, err := p.open(ctx)
+			if err != nil {
+				p.releaseAfterError(err)
+				return nil, err
+			}
+			return c, nil
+		case <-p.done:
+			return nil, ErrPoolClosed
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Put returns c to p. err is the last error returned by a command on c.
+// If err indicates that the session can no longer be used, c is closed
+// and its slot freed for a replacement.
+func (p *Pool) Put(c *Conn, err error) {
+	if c == nil {
+		return
+	}
+	if !sessionUsable(c, err) {
+		p.discard(c)
+		return
+	}
+	// Check for Close and return c to idle together, so Close cannot drain
+	// idle in between. The send never blocks, as idle holds every session.
+	p.mClose.Lock()
+	select {
+	case <-p.done:
+		p.mClose.Unlock()
+		c.Close()
+		<-p.slots
+		return
+	default:
+	}
+	p.idle <- idleConn{c: c, used: time.Now()}
+	p.mClose.Unlock()
+}
+
+// Do calls f with a session from p, returning the session to p afterwards.
+func (p *Pool) Do(ctx context.Context, f func(c *Conn) error) error {
+	c, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+	err = f(c)
+	p.Put(c, err)
+	return err
+}
+
+// Close logs out and closes all idle sessions and stops keepalives.
+// Sessions in use are closed when they are returned with Put.
+func (p *Pool) Close() error {
+	p.closeOnce.Do(func() {
+		p.mClose.Lock()
+		close(p.done)
+		p.mClose.Unlock()
+	})
+	p.wg.Wait()
+	for {
+		select {
+		case ic := <-p.idle:
+			ic.c.Close()
+			<-p.slots
+		default:
+			return nil
+		}
+	}
+}
+
+// open dials, handshakes and logs in a new session.
+func (p *Pool) open(ctx context.Context) (*Conn, error) {
+	nc, err := p.cfg.Dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewConnContext(ctx, nc, p.cfg.Timeout)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	c.UserAgent = p.cfg.UserAgent
+	c.Logger = p.cfg.Logger
+	c.Observer = p.cfg.Observer
+	c.RateLimiter = p.cfg.RateLimiter
+	res, err := c.LoginContext(ctx, p.cfg.User, p.cfg.Password, p.cfg.NewPassword)
+	var r *Result
+	if errors.As(err, &r) {
+		res = *r
+	}
+	if p.cfg.SecurityEvents != nil && len(res.SecurityEvents) > 0 {
+		p.cfg.SecurityEvents(res.SecurityEvents)
+	}
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	if p.cfg.Setup != nil {
+		err = p.cfg.Setup(ctx, c)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// releaseAfterError frees the slot taken for a session that failed to open.
+// If the server refused the login because its session limit was exceeded,
+// the slot is held back for SessionLimitBackoff.
+func (p *Pool) releaseAfterError(err error) {
+	if errors.Is(err, ErrSessionLimitExceeded) {
+		time.AfterFunc(p.cfg.SessionLimitBackoff, func() { <-p.slots })
+		return
+	}
+	<-p.slots
+}
+
+// discard closes c without logging out and frees its slot.
+func (p *Pool) discard(c *Conn) {
+	c.markBroken()
+	<-p.slots
+}
+
+// keepAlive periodically sends a <hello> on sessions that have been idle
+// for at least KeepAlive, discarding those that fail.
+func (p *Pool) keepAlive() {
+	defer p.wg.Done()
+	t := time.NewTicker(p.cfg.KeepAlive)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+		var stale []idleConn
+		for n := len(p.idle); n > 0; n-- {
+			select {
+			case ic := <-p.idle:
+				if time.Since(ic.used) < p.cfg.KeepAlive {
+					p.idle <- ic
+					continue
+				}
+				stale = append(stale, ic)
+			default:
+			}
+		}
+		for _, ic := range stale {
+			ctx, cancel := context.WithTimeout(context.Background(), p.cfg.KeepAlive)
+			err := ic.c.HelloContext(ctx)
+			cancel()
+			if err != nil {
+				p.discard(ic.c)
+				continue
+			}
+			p.Put(ic.c, nil)
+		}
+	}
+}
+
+// sessionUsable reports whether c can be reused after a command returned err.
+// A session is discarded if it is broken, on a fatal protocol error (2500
+// and above), or on a transport error. Other errors leave it intact,
+// including canceled or expired contexts, since Conn keeps the frame stream
+// in sync, commands refused by a RateLimiter or by validation, which were
+// never sent, and errors returned by the caller's own code.
+func sessionUsable(c *Conn, err error) bool {
+	if c.broken.Load() {
+		return false
+	}
+	if err == nil {
+		return true
+	}
+	var r *Result
+	if errors.As(err, &r) {
+		return !r.IsFatal()
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return !isTransportError(err)
+}
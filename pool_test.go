@@ -0,0 +1,269 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+const testXMLResultOK = `<epp><response><result code="1000"><msg>Command completed successfully</msg></result></response></epp>`
+
+// serveTestSessions accepts connections on ls until it is closed, sending a
+// greeting and answering each request with the response returned by respond.
+// Responses to <hello> and <logout> are handled automatically. If respond
+// returns "", the connection is dropped without a response.
+func serveTestSessions(ls *localServer, respond func(req string) string) {
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		var wg sync.WaitGroup
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				writeDataUnit(conn, []byte(testXMLGreeting))
+				for {
+					x, err := readTestRequest(conn)
+					if err != nil {
+						return
+					}
+					req := string(x)
+					switch {
+					case strings.Contains(req, "<hello/>"):
+						writeDataUnit(conn, []byte(testXMLGreeting))
+					case strings.Contains(req, "<logout/>"):
+						writeDataUnit(conn, []byte(`<epp><response><result code="1500"><msg>Command completed successfully; ending session</msg></result></response></epp>`))
+						return
+					default:
+						res := respond(req)
+						if res == "" {
+							// Drop the connection without responding.
+							return
+						}
+						writeDataUnit(conn, []byte(res))
+					}
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func testPoolConfig(ls *localServer) PoolConfig {
+	return PoolConfig{
+		Dial: func(ctx context.Context) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+		},
+		User:     "user",
+		Password: "password",
+	}
+}
+
+func TestPoolReusesSessions(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var logins atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<login>") {
+			logins.Add(1)
+		}
+		return testXMLResultOK
+	})
+
+	cfg := testPoolConfig(ls)
+	cfg.MaxSessions = 2
+	p := NewPool(cfg)
+	defer p.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		err = p.Do(ctx, func(c *Conn) error {
+			_, err := c.DomainInfoContext(ctx, "example.com", nil)
+			return err
+		})
+		st.Expect(t, err, nil)
+	}
+	st.Expect(t, logins.Load(), int32(1))
+
+	// Two concurrent sessions, a third caller must wait.
+	c1, err := p.Get(ctx)
+	st.Assert(t, err, nil)
+	c2, err := p.Get(ctx)
+	st.Assert(t, err, nil)
+	st.Reject(t, c1, c2)
+	st.Expect(t, logins.Load(), int32(2))
+
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	_, err = p.Get(tctx)
+	cancel()
+	st.Expect(t, err, context.DeadlineExceeded)
+
+	p.Put(c1, nil)
+	p.Put(c2, nil)
+}
+
+func TestPoolReplacesFatalSessions(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var logins atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		switch {
+		case strings.Contains(req, "<login>"):
+			logins.Add(1)
+		case strings.Contains(req, "fatal.example"):
+			return `<epp><response><result code="2500"><msg>Command failed; server closing connection</msg></result></response></epp>`
+		case strings.Contains(req, "missing.example"):
+			return `<epp><response><result code="2303"><msg>Object does not exist</msg></result></response></epp>`
+		}
+		return testXMLResultOK
+	})
+
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+	ctx := context.Background()
+
+	info := func(domain string) error {
+		return p.Do(ctx, func(c *Conn) error {
+			_, err := c.DomainInfoContext(ctx, domain, nil)
+			return err
+		})
+	}
+
+	// A non-fatal error keeps the session.
+	err = info("missing.example")
+	var r *Result
+	st.Expect(t, errors.As(err, &r), true)
+	st.Expect(t, info("example.com"), nil)
+	st.Expect(t, logins.Load(), int32(1))
+
+	// So do validation errors and errors from the caller.
+	err = p.Do(ctx, func(c *Conn) error {
+		_, err := c.DomainUpdateContext(ctx, &DomainUpdate{})
+		return err
+	})
+	var verr *ValidationError
+	st.Expect(t, errors.As(err, &verr), true)
+	errApp := errors.New("application error")
+	err = p.Do(ctx, func(c *Conn) error { return errApp })
+	st.Expect(t, err, errApp)
+	st.Expect(t, info("example.com"), nil)
+	st.Expect(t, logins.Load(), int32(1))
+
+	// A fatal error replaces it.
+	err = info("fatal.example")
+	st.Expect(t, errors.As(err, &r), true)
+	st.Expect(t, r.Code, 2500)
+	st.Expect(t, info("example.com"), nil)
+	st.Expect(t, logins.Load(), int32(2))
+}
+
+func TestPoolSessionLimit(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var logins atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<login>") && logins.Add(1) > 1 {
+			return `<epp><response><result code="2502"><msg>Session limit exceeded; server closing connection</msg></result></response></epp>`
+		}
+		return testXMLResultOK
+	})
+
+	cfg := testPoolConfig(ls)
+	cfg.MaxSessions = 3
+	cfg.SessionLimitBackoff = time.Hour
+	p := NewPool(cfg)
+	defer p.Close()
+	ctx := context.Background()
+
+	c1, err := p.Get(ctx)
+	st.Assert(t, err, nil)
+	_, err = p.Get(ctx)
+	var r *Result
+	st.Expect(t, errors.As(err, &r), true)
+	st.Expect(t, r.Code, 2502)
+
+	// The refused slot is held back, so only one more session may be opened.
+	st.Expect(t, len(p.slots), 2)
+	p.Put(c1, nil)
+}
+
+func TestPoolKeepAlive(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		return testXMLResultOK
+	})
+
+	cfg := testPoolConfig(ls)
+	cfg.KeepAlive = 10 * time.Millisecond
+	p := NewPool(cfg)
+	ctx := context.Background()
+
+	c, err := p.Get(ctx)
+	st.Assert(t, err, nil)
+	p.Put(c, nil)
+	time.Sleep(50 * time.Millisecond)
+
+	c2, err := p.Get(ctx)
+	st.Assert(t, err, nil)
+	st.Expect(t, c2, c)
+	p.Put(c2, nil)
+	st.Expect(t, p.Close(), nil)
+
+	_, err = p.Get(ctx)
+	st.Expect(t, err, ErrPoolClosed)
+}
+
+func TestPoolPutDuringClose(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		return testXMLResultOK
+	})
+
+	for i := 0; i < 20; i++ {
+		cfg := testPoolConfig(ls)
+		cfg.MaxSessions = 4
+		p := NewPool(cfg)
+		var conns []*Conn
+		for j := 0; j < cfg.MaxSessions; j++ {
+			c, err := p.Get(context.Background())
+			st.Assert(t, err, nil)
+			conns = append(conns, c)
+		}
+
+		var wg sync.WaitGroup
+		for _, c := range conns {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.Put(c, nil)
+			}()
+		}
+		p.Close()
+		wg.Wait()
+
+		// Every session was closed and its slot released.
+		st.Expect(t, len(p.idle), 0)
+		st.Expect(t, len(p.slots), 0)
+		for _, c := range conns {
+			st.Expect(t, c.Err(), net.ErrClosed)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExceeded is returned by RateLimiter.Wait when the daily quota
+// of check commands has been used.
+var ErrQuotaExceeded = errors.New("epp: daily check quota exceeded")
+
+// Command classes for RateLimiterConfig.Commands.
+const (
+	ClassQuery     = "query"     // check, info and poll
+	ClassTransform = "transform" // create, delete, renew, transfer and update, including RGP restores
+)
+
+// RateLimit is a rate of Count commands per Per, allowing bursts of up to
+// Burst commands. A zero Count or Per means no limit.
+type RateLimit struct {
+	Count int
+	Per   time.Duration
+
+	// Burst is the number of commands that may be sent at once.
+	// It defaults to 1, so commands are spaced evenly and the rate
+	// is never exceeded in any window of length Per.
+	Burst int
+}
+
+// RateLimiterConfig configures a RateLimiter.
+type RateLimiterConfig struct {
+	// All limits every command, including <hello>, <login> and <logout>.
+	All RateLimit
+
+	// Commands limits commands by element name, such as "check" or
+	// "create", or by class, ClassQuery or ClassTransform. A command is
+	// subject to All, the limit for its class and the limit for its name.
+	// An RGP restore is sent as an update command, so it is limited as
+	// "update".
+	Commands map[string]RateLimit
+
+	// DailyChecks is the number of check commands allowed per day.
+	// Zero means no quota. Once the quota is used, checks fail with
+	// ErrQuotaExceeded until the next day. The count is kept in memory.
+	DailyChecks int
+
+	// Location defines the start of each day for DailyChecks.
+	// Defaults to UTC.
+	Location *time.Location
+}
+
+// RateLimiter limits the rate of commands sent to a registry. Callers wait
+// in turn until their command is allowed, rather than failing. To share its
+// limits across sessions, set the same RateLimiter on each Conn, or in the
+// PoolConfig of each pool for a registry profile.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	all      *bucket
+	commands map[string]*bucket
+	checks   int
+	loc      *time.Location
+
+	m         sync.Mutex
+	day       time.Time // start of the current day
+	checksDay int       // checks sent since day
+}
+
+// NewRateLimiter returns a RateLimiter configured by cfg.
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	l := &RateLimiter{
+		all:      newBucket(cfg.All),
+		commands: make(map[string]*bucket),
+		checks:   cfg.DailyChecks,
+		loc:      cfg.Location,
+	}
+	if l.loc == nil {
+		l.loc = time.UTC
+	}
+	for name, limit := range cfg.Commands {
+		if b := newBucket(limit); b != nil {
+			l.commands[name] = b
+		}
+	}
+	return l
+}
+
+// commandClass returns the class of command.
+func commandClass(command string) string {
+	switch command {
+	case "check", "info", "poll":
+		return ClassQuery
+	case "create", "delete", "renew", "transfer", "update":
+		return ClassTransform
+	}
+	return ""
+}
+
+// Wait blocks until command, such as "check", may be sent, or until ctx
+// is done. It returns ErrQuotaExceeded if command is a check and the daily
+// quota has been used, and fails early if ctx expires before command would
+// be allowed.
+func (l *RateLimiter) Wait(ctx context.Context, command string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	if command == "check" {
+		if err := l.takeCheck(now); err != nil {
+			return err
+		}
+	}
+
+	var buckets []*bucket
+	for _, b := range []*bucket{l.all, l.commands[commandClass(command)], l.commands[command]} {
+		if b != nil {
+			buckets = append(buckets, b)
+		}
+	}
+	var delay time.Duration
+	for _, b := range buckets {
+		delay = max(delay, b.reserve(now))
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	cancel := func() {
+		for _, b := range buckets {
+			b.cancel()
+		}
+		if command == "check" {
+			l.returnCheck(now)
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		cancel()
+		return context.DeadlineExceeded
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// ChecksToday returns the number of check commands allowed today.
+func (l *RateLimiter) ChecksToday() int {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.startOfDay(time.Now()) != l.day {
+		return 0
+	}
+	return l.checksDay
+}
+
+// unsent returns the quota taken by Wait for command, which was not sent
+// because writing it failed.
+func (l *RateLimiter) unsent(command string) {
+	if command == "check" {
+		l.returnCheck(time.Now())
+	}
+}
+
+// takeCheck counts a check against the daily quota.
+func (l *RateLimiter) takeCheck(now time.Time) error {
+	if l.checks <= 0 {
+		return nil
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	if day := l.startOfDay(now); day != l.day {
+		l.day = day
+		l.checksDay = 0
+	}
+	if l.checksDay >= l.checks {
+		return ErrQuotaExceeded
+	}
+	l.checksDay++
+	return nil
+}
+
+// returnCheck undoes takeCheck for a check that was not sent.
+func (l *RateLimiter) returnCheck(now time.Time) {
+	if l.checks <= 0 {
+		return
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.startOfDay(now) == l.day && l.checksDay > 0 {
+		l.checksDay--
+	}
+}
+
+func (l *RateLimiter) startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(l.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, l.loc)
+}
+
+// bucket is a token bucket. Tokens are reserved in turn, so the balance
+// goes negative while callers are queued.
+type bucket struct {
+	interval time.Duration // time to add one token
+	burst    float64
+
+	m      sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newBucket returns a bucket for limit, or nil if limit is unlimited.
+func newBucket(limit RateLimit) *bucket {
+	if limit.Count <= 0 || limit.Per <= 0 {
+		return nil
+	}
+	burst := float64(max(limit.Burst, 1))
+	return &bucket{
+		interval: limit.Per / time.Duration(limit.Count),
+		burst:    burst,
+		tokens:   burst,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait for it.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.m.Lock()
+	defer b.m.Unlock()
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.interval))
+	}
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.interval))
+}
+
+// cancel returns a token reserved by a caller that stopped waiting.
+func (b *bucket) cancel() {
+	b.m.Lock()
+	defer b.m.Unlock()
+	b.tokens = min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,146 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func TestRateLimiterSpacesCommands(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{
+		All: RateLimit{Count: 100, Per: time.Second},
+	})
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		st.Assert(t, l.Wait(ctx, "hello"), nil)
+	}
+	// The first command is sent at once, and each other after 10ms.
+	st.Expect(t, time.Since(start) >= 40*time.Millisecond, true)
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{
+		All: RateLimit{Count: 1, Per: time.Hour, Burst: 3},
+	})
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		st.Assert(t, l.Wait(ctx, "info"), nil)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	st.Expect(t, l.Wait(ctx, "info"), context.DeadlineExceeded)
+}
+
+func TestRateLimiterCommandClasses(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{
+		Commands: map[string]RateLimit{
+			ClassQuery: {Count: 1, Per: time.Hour},
+			"create":   {Count: 1, Per: time.Hour},
+		},
+	})
+	ctx := context.Background()
+	st.Assert(t, l.Wait(ctx, "check"), nil)
+	st.Assert(t, l.Wait(ctx, "create"), nil)
+	st.Assert(t, l.Wait(ctx, "update"), nil)
+	st.Assert(t, l.Wait(ctx, "update"), nil)
+
+	// An RGP restore is limited as an update.
+	x, err := encodeDomainRestore(&Greeting{}, "example.com", nil)
+	st.Assert(t, err, nil)
+	cmd, _ := commandName(x)
+	st.Expect(t, cmd, "update")
+	st.Expect(t, commandClass(cmd), ClassTransform)
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { done <- l.Wait(ctx, "info") }()
+	select {
+	case err := <-done:
+		t.Fatalf("Wait returned %v, want it to queue", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	st.Expect(t, <-done, context.Canceled)
+}
+
+func TestRateLimiterDailyChecks(t *testing.T) {
+	l := NewRateLimiter(RateLimiterConfig{DailyChecks: 2})
+	ctx := context.Background()
+	st.Expect(t, l.ChecksToday(), 0)
+	st.Assert(t, l.Wait(ctx, "check"), nil)
+	st.Assert(t, l.Wait(ctx, "check"), nil)
+	st.Expect(t, l.Wait(ctx, "check"), ErrQuotaExceeded)
+	st.Expect(t, l.Wait(ctx, "info"), nil)
+	st.Expect(t, l.ChecksToday(), 2)
+
+	// A new day resets the quota.
+	l.day = l.day.AddDate(0, 0, -1)
+	st.Expect(t, l.ChecksToday(), 0)
+	st.Expect(t, l.Wait(ctx, "check"), nil)
+	st.Expect(t, l.ChecksToday(), 1)
+}
+
+func TestRateLimiterUnsentCheck(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	c.RateLimiter = NewRateLimiter(RateLimiterConfig{DailyChecks: 1})
+
+	// A check that could not be written does not use the quota.
+	nc.Close()
+	_, err = c.CheckDomain("example.com")
+	st.Expect(t, errors.Is(err, net.ErrClosed), true)
+	st.Expect(t, c.RateLimiter.ChecksToday(), 0)
+}
+
+func TestPoolRateLimiter(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var checks, logins atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		if strings.Contains(req, "<login>") {
+			logins.Add(1)
+		}
+		if strings.Contains(req, "<check>") {
+			checks.Add(1)
+		}
+		return testXMLResultOK
+	})
+
+	cfg := testPoolConfig(ls)
+	cfg.MaxSessions = 2
+	cfg.RateLimiter = NewRateLimiter(RateLimiterConfig{DailyChecks: 3})
+	p := NewPool(cfg)
+	defer p.Close()
+
+	ctx := context.Background()
+	check := func(c *Conn) error {
+		_, err := c.CheckDomainContext(ctx, "example.com")
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		st.Assert(t, p.Do(ctx, check), nil)
+	}
+	err = p.Do(ctx, check)
+	st.Expect(t, errors.Is(err, ErrQuotaExceeded), true)
+	st.Expect(t, checks.Load(), int32(3))
+	st.Expect(t, logins.Load() <= 2, true)
+}
@@ -2,6 +2,7 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"time"
 
@@ -11,15 +12,16 @@ import (
 // RenewDomain requests the renewal of a domain.
 // https://tools.ietf.org/html/rfc5731#section-3.2.2
 func (c *Conn) RenewDomain(domain string, curExpDate time.Time, period int, unit string, extData map[string]string) (*DomainRenewResponse, error) {
+	return c.RenewDomainContext(context.Background(), domain, curExpDate, period, unit, extData)
+}
+
+// RenewDomainContext is like RenewDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) RenewDomainContext(ctx context.Context, domain string, curExpDate time.Time, period int, unit string, extData map[string]string) (*DomainRenewResponse, error) {
 	x, err := encodeDomainRenew(&c.Greeting, domain, curExpDate, period, unit, extData)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -48,20 +50,9 @@ func encodeDomainRenew(greeting *Greeting, domain string, curExpDate time.Time,
 
 	buf.WriteString(`</domain:renew></renew>`)
 
-	if fee, ok := extData["fee:fee"]; ok {
+	if _, ok := extData["fee:fee"]; ok {
 		buf.WriteString(`<extension>`)
-		buf.WriteString(`<fee:renew xmlns:fee="`)
-		buf.WriteString(ExtFee10)
-		buf.WriteString(`">`)
-		if currency, ok := extData["fee:currency"]; ok {
-			buf.WriteString(`<fee:currency>`)
-			buf.WriteString(currency)
-			buf.WriteString(`</fee:currency>`)
-		}
-		buf.WriteString(`<fee:fee>`)
-		buf.WriteString(fee)
-		buf.WriteString(`</fee:fee>`)
-		buf.WriteString(`</fee:renew>`)
+		encodeFee(buf, greeting, "renew", extData)
 		buf.WriteString(`</extension>`)
 	}
 
@@ -73,6 +64,8 @@ func encodeDomainRenew(greeting *Greeting, domain string, curExpDate time.Time,
 type DomainRenewResponse struct {
 	Domain string    // <domain:name>
 	ExDate time.Time // <domain:exDate>
+	Fee    FeeData   // <fee:renData>
+	TransactionID
 }
 
 func init() {
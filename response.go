@@ -2,7 +2,6 @@ package epp
 
 import "github.com/nbio/xx"
 
-// Response represents an EPP response.
 // Response represents an EPP response.
 type Response struct {
 	Result
@@ -18,6 +17,30 @@ type Response struct {
 	ContactCreateResponse
 	ContactInfoResponse
 	PollResponse
+	HostCheckResponse
+	HostInfoResponse
+	ContactCheckResponse
+	ContactTransferResponse
+}
+
+// setTransactionID copies the transaction identifiers scanned into
+// r.Result to each of the typed responses embedded in r.
+func (r *Response) setTransactionID() {
+	id := r.Result.TransactionID
+	r.DomainCheckResponse.TransactionID = id
+	r.DomainInfoResponse.TransactionID = id
+	r.DomainCreateResponse.TransactionID = id
+	r.HostCreateResponse.TransactionID = id
+	r.DomainRenewResponse.TransactionID = id
+	r.DomainTransferResponse.TransactionID = id
+	r.DomainUpdateResponse.TransactionID = id
+	r.ContactCreateResponse.TransactionID = id
+	r.ContactInfoResponse.TransactionID = id
+	r.PollResponse.TransactionID = id
+	r.HostCheckResponse.TransactionID = id
+	r.HostInfoResponse.TransactionID = id
+	r.ContactCheckResponse.TransactionID = id
+	r.ContactTransferResponse.TransactionID = id
 }
 
 var scanResponse = xx.NewScanner()
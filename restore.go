@@ -2,21 +2,27 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"time"
+
+	"github.com/nbio/xx"
 )
 
 // RestoreDomain requests the restoration of a domain (usually via RGP extension).
 // This is actually an <update> command with an RGP extension <restore> op.
+// A restore fee is acknowledged with the "fee:fee" and "fee:currency" extData keys.
 func (c *Conn) RestoreDomain(domain string, extData map[string]string) (*DomainUpdateResponse, error) {
+	return c.RestoreDomainContext(context.Background(), domain, extData)
+}
+
+// RestoreDomainContext is like RestoreDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) RestoreDomainContext(ctx context.Context, domain string, extData map[string]string) (*DomainUpdateResponse, error) {
 	x, err := encodeDomainRestore(&c.Greeting, domain, extData)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -25,29 +31,155 @@ func (c *Conn) RestoreDomain(domain string, extData map[string]string) (*DomainU
 
 func encodeDomainRestore(greeting *Greeting, domain string, extData map[string]string) ([]byte, error) {
 	buf := bytes.NewBufferString(xmlCommandPrefix)
+	encodeRestoreUpdate(buf, domain)
+
+	// RGP Extension for restore
+	// https://tools.ietf.org/html/rfc3915
+	buf.WriteString(`<extension><rgp:update xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0">`)
+	buf.WriteString(`<rgp:restore op="request"/>`)
+	buf.WriteString(`</rgp:update>`)
+	encodeFee(buf, greeting, "update", extData)
+	buf.WriteString(`</extension>`)
+
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// encodeRestoreUpdate writes the empty <domain:update> that carries an RGP restore.
+func encodeRestoreUpdate(buf *bytes.Buffer, domain string) {
 	buf.WriteString(`<update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
 	buf.WriteString(`<domain:name>`)
 	xml.EscapeText(buf, []byte(domain))
 	buf.WriteString(`</domain:name>`)
 	buf.WriteString(`</domain:update></update>`)
+}
+
+// RestoreReport is the report a registrar sends to complete the restore of
+// a domain in redemption, after the restore request has been accepted.
+// https://tools.ietf.org/html/rfc3915#section-4.2.5
+type RestoreReport struct {
+	PreData    string    // <rgp:preData>: registration data before deletion
+	PostData   string    // <rgp:postData>: registration data after restore
+	DelTime    time.Time // <rgp:delTime>: when the domain was deleted
+	ResTime    time.Time // <rgp:resTime>: when the restore request was sent
+	ResReason  string    // <rgp:resReason>: why the domain was restored
+	Statements []string  // <rgp:statement>: the two registrar statements
+	Other      string    // optional <rgp:other>
+	Lang       string    // optional lang attribute of resReason and statements
+}
+
+// Validate returns a ValidationError if r is not a complete restore report.
+func (r *RestoreReport) Validate() error {
+	if err := validateRequired("PreData", r.PreData); err != nil {
+		return err
+	}
+	if err := validateRequired("PostData", r.PostData); err != nil {
+		return err
+	}
+	if r.DelTime.IsZero() {
+		return invalid("DelTime", "required")
+	}
+	if r.ResTime.IsZero() {
+		return invalid("ResTime", "required")
+	}
+	if err := validateRequired("ResReason", r.ResReason); err != nil {
+		return err
+	}
+	if len(r.Statements) != 2 {
+		return invalid("Statements", "%d statements, want 2", len(r.Statements))
+	}
+	for _, s := range r.Statements {
+		if err := validateRequired("Statements", s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreDomainReport sends the restore report for domain, the second step of
+// a restore under the Redemption Grace Period.
+// https://tools.ietf.org/html/rfc3915#section-4.2.5
+func (c *Conn) RestoreDomainReport(domain string, report *RestoreReport) (*DomainUpdateResponse, error) {
+	return c.RestoreDomainReportContext(context.Background(), domain, report)
+}
+
+// RestoreDomainReportContext is like RestoreDomainReport, but honors cancellation and deadlines from ctx.
+func (c *Conn) RestoreDomainReportContext(ctx context.Context, domain string, report *RestoreReport) (*DomainUpdateResponse, error) {
+	x, err := encodeDomainRestoreReport(&c.Greeting, domain, report)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainUpdateResponse, nil
+}
+
+func encodeDomainRestoreReport(greeting *Greeting, domain string, report *RestoreReport) ([]byte, error) {
+	if err := validateRequired("Domain", domain); err != nil {
+		return nil, err
+	}
+	if err := report.Validate(); err != nil {
+		return nil, err
+	}
+	lang := ""
+	if report.Lang != "" {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(report.Lang))
+		lang = ` lang="` + b.String() + `"`
+	}
+
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	encodeRestoreUpdate(buf, domain)
 
-	// RGP Extension for restore
-	// https://tools.ietf.org/html/rfc3915
 	buf.WriteString(`<extension><rgp:update xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0">`)
-	buf.WriteString(`<rgp:restore op="request"/>`)
+	buf.WriteString(`<rgp:restore op="report"><rgp:report>`)
+	buf.WriteString(`<rgp:preData>`)
+	xml.EscapeText(buf, []byte(report.PreData))
+	buf.WriteString(`</rgp:preData>`)
+	buf.WriteString(`<rgp:postData>`)
+	xml.EscapeText(buf, []byte(report.PostData))
+	buf.WriteString(`</rgp:postData>`)
+	buf.WriteString(`<rgp:delTime>`)
+	buf.WriteString(report.DelTime.UTC().Format(time.RFC3339))
+	buf.WriteString(`</rgp:delTime>`)
+	buf.WriteString(`<rgp:resTime>`)
+	buf.WriteString(report.ResTime.UTC().Format(time.RFC3339))
+	buf.WriteString(`</rgp:resTime>`)
+	buf.WriteString(`<rgp:resReason` + lang + `>`)
+	xml.EscapeText(buf, []byte(report.ResReason))
+	buf.WriteString(`</rgp:resReason>`)
+	for _, s := range report.Statements {
+		buf.WriteString(`<rgp:statement` + lang + `>`)
+		xml.EscapeText(buf, []byte(s))
+		buf.WriteString(`</rgp:statement>`)
+	}
+	if report.Other != "" {
+		buf.WriteString(`<rgp:other>`)
+		xml.EscapeText(buf, []byte(report.Other))
+		buf.WriteString(`</rgp:other>`)
+	}
+	buf.WriteString(`</rgp:report></rgp:restore>`)
 	buf.WriteString(`</rgp:update></extension>`)
 
 	buf.WriteString(xmlCommandSuffix)
 	return buf.Bytes(), nil
 }
 
-// DomainUpdateResponse might be generic, but for now defining it here if not exists.
-// Logic check: does DomainUpdateResponse exist? No.
+// DomainUpdateResponse represents an EPP response for a domain update,
+// including restore requests and reports.
 type DomainUpdateResponse struct {
-	// Usually empty resData for update?
-	// RFC 5731 says <domain:upData> is optional and currently not defined to return anything useful other than basic success.
+	RGPStatus []string // <rgp:upData><rgp:rgpStatus s="...">
+	Fee       FeeData  // <fee:upData>
+	TransactionID
 }
 
 func init() {
-	// No specific scan logic needed for update response data yet as it is usually empty
+	path := "epp > response > extension > " + ExtRGP + " upData"
+	scanResponse.MustHandleStartElement(path+">rgpStatus", func(c *xx.Context) error {
+		dur := &c.Value.(*Response).DomainUpdateResponse
+		dur.RGPStatus = append(dur.RGPStatus, c.Attr("", "s"))
+		return nil
+	})
 }
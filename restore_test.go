@@ -2,7 +2,10 @@ package epp
 
 import (
 	"encoding/xml"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -18,3 +21,93 @@ func TestEncodeDomainRestore(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestEncodeDomainRestoreReport(t *testing.T) {
+	report := &RestoreReport{
+		PreData:    "Pre-delete registration data.",
+		PostData:   "Post-restore registration data.",
+		DelTime:    time.Date(2003, 7, 10, 22, 0, 0, 0, time.UTC),
+		ResTime:    time.Date(2003, 7, 20, 22, 0, 0, 0, time.UTC),
+		ResReason:  "Registrant error.",
+		Statements: []string{"Statement one.", "Statement two & more."},
+		Other:      "Supporting information.",
+		Lang:       "en",
+	}
+	x, err := encodeDomainRestoreReport(nil, "example.com", report)
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:update></update><extension><rgp:update xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0"><rgp:restore op="report"><rgp:report><rgp:preData>Pre-delete registration data.</rgp:preData><rgp:postData>Post-restore registration data.</rgp:postData><rgp:delTime>2003-07-10T22:00:00Z</rgp:delTime><rgp:resTime>2003-07-20T22:00:00Z</rgp:resTime><rgp:resReason lang="en">Registrant error.</rgp:resReason><rgp:statement lang="en">Statement one.</rgp:statement><rgp:statement lang="en">Statement two &amp; more.</rgp:statement><rgp:other>Supporting information.</rgp:other></rgp:report></rgp:restore></rgp:update></extension></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestEncodeDomainRestoreReportLangEscaped(t *testing.T) {
+	report := &RestoreReport{
+		PreData:    "pre",
+		PostData:   "post",
+		DelTime:    time.Date(2003, 7, 10, 22, 0, 0, 0, time.UTC),
+		ResTime:    time.Date(2003, 7, 20, 22, 0, 0, 0, time.UTC),
+		ResReason:  "reason",
+		Statements: []string{"one", "two"},
+		Lang:       `en"><x`,
+	}
+	x, err := encodeDomainRestoreReport(nil, "example.com", report)
+	st.Expect(t, err, nil)
+	st.Expect(t, strings.Contains(string(x), `<rgp:resReason lang="en&#34;&gt;&lt;x">reason</rgp:resReason>`), true)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestRestoreReportValidate(t *testing.T) {
+	report := RestoreReport{
+		PreData:    "pre",
+		PostData:   "post",
+		DelTime:    time.Now(),
+		ResTime:    time.Now(),
+		ResReason:  "reason",
+		Statements: []string{"one", "two"},
+	}
+	st.Expect(t, report.Validate(), nil)
+
+	var verr *ValidationError
+	r := report
+	r.DelTime = time.Time{}
+	st.Assert(t, errors.As(r.Validate(), &verr), true)
+	st.Expect(t, verr.Field, "DelTime")
+
+	r = report
+	r.Statements = []string{"one"}
+	_, err := encodeDomainRestoreReport(nil, "example.com", &r)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Statements")
+}
+
+func TestScanDomainRestoreResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <extension>
+      <rgp:upData xmlns:rgp="urn:ietf:params:xml:ns:rgp-1.0">
+        <rgp:rgpStatus s="pendingRestore"/>
+      </rgp:upData>
+    </extension>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54321-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainUpdateResponse.RGPStatus, []string{"pendingRestore"})
+}
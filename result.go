@@ -1,7 +1,12 @@
 package epp
 
 import (
+	"bytes"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/nbio/xx"
 )
@@ -10,40 +15,387 @@ import (
 type Result struct {
 	Code    int    `xml:"code,attr"`
 	Message string `xml:"msg"`
+	Lang    string `xml:"-"` // <msg lang="...">
 	Reason  string `xml:"extValue>reason,omitempty"`
+
+	// Values holds the raw XML of each <value> element, identifying
+	// the client-provided element that caused an error.
+	Values []string `xml:"-"`
+
+	// ExtValues holds each <extValue> element, pairing the offending
+	// element with a server-supplied reason.
+	ExtValues []ExtValue `xml:"-"`
+
+	// Others holds any further <result> elements in the same response,
+	// in document order. It is only set on the first result.
+	Others []Result `xml:"-"`
+
+	// SecurityEvents holds the <loginSec:event> elements returned by a
+	// login, such as an upcoming password or certificate expiry.
+	SecurityEvents []SecurityEvent `xml:"-"`
+
+	TransactionID
+}
+
+// ExtValue represents an EPP <extValue> element.
+// https://tools.ietf.org/html/rfc5730#section-2.6
+type ExtValue struct {
+	Value  string // raw XML of <value>
+	Reason string // <reason>
+	Lang   string // <reason lang="...">
 }
 
 // IsError determines whether an EPP status code is an error.
 // https://tools.ietf.org/html/rfc5730#section-3
 func (r *Result) IsError() bool {
-	return r.Code >= 2000
+	return r.Code >= CodeUnknownCommand
 }
 
 // IsFatal determines whether an EPP status code is a fatal response,
 // and the connection should be closed.
 // https://tools.ietf.org/html/rfc5730#section-3
 func (r *Result) IsFatal() bool {
-	return r.Code >= 2500
+	return r.Code >= CodeCommandFailedClosing
+}
+
+// IsRetryable determines whether the command that produced r may succeed
+// if retried later, possibly on a new session.
+func (r *Result) IsRetryable() bool {
+	switch r.Code {
+	case CodeCommandFailed, CodeCommandFailedClosing, CodeSessionLimitExceeded:
+		return true
+	}
+	return false
+}
+
+// IsAuth determines whether an EPP status code is an authentication or
+// authorization error.
+func (r *Result) IsAuth() bool {
+	switch r.Code {
+	case CodeAuthentication, CodeAuthorization, CodeInvalidAuthInfo, CodeAuthenticationClosing:
+		return true
+	}
+	return false
+}
+
+// IsObjectState determines whether an EPP status code indicates that the
+// command conflicts with the existence or state of the object, such as
+// an object that already exists or has a status prohibiting the operation.
+func (r *Result) IsObjectState() bool {
+	switch r.Code {
+	case CodeNotRenewable, CodeNotTransferable:
+		return true
+	}
+	return r.Code >= CodePendingTransfer && r.Code <= CodeAssociationProhibits
 }
 
 // Error implements the error interface.
+// Reasons and any further results are appended to the message.
 func (r *Result) Error() string {
-	return fmt.Sprintf("EPP result code %d: %s", r.Code, r.Message)
+	msg := r.Message
+	if msg == "" {
+		msg = resultMessages[r.Code]
+	}
+	s := fmt.Sprintf("EPP result code %d: %s", r.Code, msg)
+	for _, v := range r.ExtValues {
+		if v.Reason != "" {
+			s += " (" + v.Reason + ")"
+		}
+	}
+	if len(r.ExtValues) == 0 && r.Reason != "" {
+		s += " (" + r.Reason + ")"
+	}
+	for i := range r.Others {
+		s += "; " + r.Others[i].Error()
+	}
+	return s
+}
+
+// Unwrap returns the further error results in the same response,
+// so errors.Is and errors.As also match those.
+func (r *Result) Unwrap() []error {
+	var errs []error
+	for i := range r.Others {
+		if r.Others[i].IsError() {
+			errs = append(errs, &r.Others[i])
+		}
+	}
+	return errs
+}
+
+// Is reports whether target is a *Result with the same code as r,
+// so errors can be compared with the sentinel errors in this package:
+//
+//	if errors.Is(err, epp.ErrObjectNotFound) { ... }
+func (r *Result) Is(target error) bool {
+	t, ok := target.(*Result)
+	return ok && t.Code == r.Code
+}
+
+// IsRetryable reports whether err is an EPP result for a command
+// that may succeed if retried. See Result.IsRetryable.
+func IsRetryable(err error) bool {
+	var r *Result
+	return errors.As(err, &r) && r.IsRetryable()
+}
+
+// IsAuthError reports whether err is an EPP authentication or
+// authorization error. See Result.IsAuth.
+func IsAuthError(err error) bool {
+	var r *Result
+	return errors.As(err, &r) && r.IsAuth()
+}
+
+// IsObjectStateError reports whether err is an EPP result indicating that
+// the command conflicts with the state of the object. See Result.IsObjectState.
+func IsObjectStateError(err error) bool {
+	var r *Result
+	return errors.As(err, &r) && r.IsObjectState()
+}
+
+// IsFatalError reports whether err is an EPP result after which the server
+// closes the connection. See Result.IsFatal.
+func IsFatalError(err error) bool {
+	var r *Result
+	return errors.As(err, &r) && r.IsFatal()
+}
+
+// EPP result codes.
+// https://tools.ietf.org/html/rfc5730#section-3
+const (
+	CodeSuccess                    = 1000
+	CodeSuccessPending             = 1001
+	CodeNoMessages                 = 1300
+	CodeAckToDequeue               = 1301
+	CodeEndingSession              = 1500
+	CodeUnknownCommand             = 2000
+	CodeSyntaxError                = 2001
+	CodeUseError                   = 2002
+	CodeParameterMissing           = 2003
+	CodeParameterRange             = 2004
+	CodeParameterSyntax            = 2005
+	CodeUnimplementedVersion       = 2100
+	CodeUnimplementedCommand       = 2101
+	CodeUnimplementedOption        = 2102
+	CodeUnimplementedExtension     = 2103
+	CodeBillingFailure             = 2104
+	CodeNotRenewable               = 2105
+	CodeNotTransferable            = 2106
+	CodeAuthentication             = 2200
+	CodeAuthorization              = 2201
+	CodeInvalidAuthInfo            = 2202
+	CodePendingTransfer            = 2300
+	CodeNotPendingTransfer         = 2301
+	CodeObjectExists               = 2302
+	CodeObjectNotFound             = 2303
+	CodeStatusProhibits            = 2304
+	CodeAssociationProhibits       = 2305
+	CodeParameterPolicy            = 2306
+	CodeUnimplementedObjectService = 2307
+	CodeDataManagementPolicy       = 2308
+	CodeCommandFailed              = 2400
+	CodeCommandFailedClosing       = 2500
+	CodeAuthenticationClosing      = 2501
+	CodeSessionLimitExceeded       = 2502
+)
+
+// resultMessages holds the text defined in RFC 5730 for each result code.
+var resultMessages = map[int]string{
+	CodeSuccess:                    "Command completed successfully",
+	CodeSuccessPending:             "Command completed successfully; action pending",
+	CodeNoMessages:                 "Command completed successfully; no messages",
+	CodeAckToDequeue:               "Command completed successfully; ack to dequeue",
+	CodeEndingSession:              "Command completed successfully; ending session",
+	CodeUnknownCommand:             "Unknown command",
+	CodeSyntaxError:                "Command syntax error",
+	CodeUseError:                   "Command use error",
+	CodeParameterMissing:           "Required parameter missing",
+	CodeParameterRange:             "Parameter value range error",
+	CodeParameterSyntax:            "Parameter value syntax error",
+	CodeUnimplementedVersion:       "Unimplemented protocol version",
+	CodeUnimplementedCommand:       "Unimplemented command",
+	CodeUnimplementedOption:        "Unimplemented option",
+	CodeUnimplementedExtension:     "Unimplemented extension",
+	CodeBillingFailure:             "Billing failure",
+	CodeNotRenewable:               "Object is not eligible for renewal",
+	CodeNotTransferable:            "Object is not eligible for transfer",
+	CodeAuthentication:             "Authentication error",
+	CodeAuthorization:              "Authorization error",
+	CodeInvalidAuthInfo:            "Invalid authorization information",
+	CodePendingTransfer:            "Object pending transfer",
+	CodeNotPendingTransfer:         "Object not pending transfer",
+	CodeObjectExists:               "Object exists",
+	CodeObjectNotFound:             "Object does not exist",
+	CodeStatusProhibits:            "Object status prohibits operation",
+	CodeAssociationProhibits:       "Object association prohibits operation",
+	CodeParameterPolicy:            "Parameter value policy error",
+	CodeUnimplementedObjectService: "Unimplemented object service",
+	CodeDataManagementPolicy:       "Data management policy violation",
+	CodeCommandFailed:              "Command failed",
+	CodeCommandFailedClosing:       "Command failed; server closing connection",
+	CodeAuthenticationClosing:      "Authentication error; server closing connection",
+	CodeSessionLimitExceeded:       "Session limit exceeded; server closing connection",
+}
+
+// Sentinel errors for EPP error result codes, for use with errors.Is.
+// A *Result returned by a command matches the sentinel with the same code.
+var (
+	ErrUnknownCommand             = newResultError(CodeUnknownCommand)
+	ErrSyntaxError                = newResultError(CodeSyntaxError)
+	ErrUseError                   = newResultError(CodeUseError)
+	ErrParameterMissing           = newResultError(CodeParameterMissing)
+	ErrParameterRange             = newResultError(CodeParameterRange)
+	ErrParameterSyntax            = newResultError(CodeParameterSyntax)
+	ErrUnimplementedVersion       = newResultError(CodeUnimplementedVersion)
+	ErrUnimplementedCommand       = newResultError(CodeUnimplementedCommand)
+	ErrUnimplementedOption        = newResultError(CodeUnimplementedOption)
+	ErrUnimplementedExtension     = newResultError(CodeUnimplementedExtension)
+	ErrBillingFailure             = newResultError(CodeBillingFailure)
+	ErrNotRenewable               = newResultError(CodeNotRenewable)
+	ErrNotTransferable            = newResultError(CodeNotTransferable)
+	ErrAuthentication             = newResultError(CodeAuthentication)
+	ErrAuthorization              = newResultError(CodeAuthorization)
+	ErrInvalidAuthInfo            = newResultError(CodeInvalidAuthInfo)
+	ErrPendingTransfer            = newResultError(CodePendingTransfer)
+	ErrNotPendingTransfer         = newResultError(CodeNotPendingTransfer)
+	ErrObjectExists               = newResultError(CodeObjectExists)
+	ErrObjectNotFound             = newResultError(CodeObjectNotFound)
+	ErrStatusProhibits            = newResultError(CodeStatusProhibits)
+	ErrAssociationProhibits       = newResultError(CodeAssociationProhibits)
+	ErrParameterPolicy            = newResultError(CodeParameterPolicy)
+	ErrUnimplementedObjectService = newResultError(CodeUnimplementedObjectService)
+	ErrDataManagementPolicy       = newResultError(CodeDataManagementPolicy)
+	ErrCommandFailed              = newResultError(CodeCommandFailed)
+	ErrCommandFailedClosing       = newResultError(CodeCommandFailedClosing)
+	ErrAuthenticationClosing      = newResultError(CodeAuthenticationClosing)
+	ErrSessionLimitExceeded       = newResultError(CodeSessionLimitExceeded)
+)
+
+func newResultError(code int) *Result {
+	return &Result{Code: code, Message: resultMessages[code]}
+}
+
+// currentResult returns the <result> being scanned.
+func (res *Response) currentResult() *Result {
+	if n := len(res.Result.Others); n > 0 {
+		return &res.Result.Others[n-1]
+	}
+	return &res.Result
+}
+
+// lastExtValue returns the <extValue> being scanned.
+func (r *Result) lastExtValue() *ExtValue {
+	if len(r.ExtValues) == 0 {
+		r.ExtValues = append(r.ExtValues, ExtValue{})
+	}
+	return &r.ExtValues[len(r.ExtValues)-1]
+}
+
+// decodeResultValues fills in the raw XML of the <value> elements of each
+// result in res from body, as the scanner only sees character data.
+// Only the <result> elements are decoded, so body may be truncated after them.
+func decodeResultValues(body []byte, res *Response) error {
+	type result struct {
+		Values []struct {
+			XML string `xml:",innerxml"`
+		} `xml:"value"`
+		ExtValues []struct {
+			Value struct {
+				XML string `xml:",innerxml"`
+			} `xml:"value"`
+		} `xml:"extValue"`
+	}
+	d := xml.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for i := 0; ; {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := t.(type) {
+		case xml.EndElement:
+			depth--
+			continue
+		case xml.StartElement:
+			depth++
+			if depth < 3 {
+				continue
+			}
+			// <epp><response><result>, which precede all other elements.
+			if t.Name.Local != "result" {
+				return nil
+			}
+			var rv result
+			err = d.DecodeElement(&rv, &t)
+			if err != nil {
+				return err
+			}
+			depth--
+			r := &res.Result
+			if i > 0 {
+				if i > len(res.Result.Others) {
+					return nil
+				}
+				r = &res.Result.Others[i-1]
+			}
+			i++
+			r.Values = r.Values[:0]
+			for _, x := range rv.Values {
+				r.Values = append(r.Values, strings.TrimSpace(x.XML))
+			}
+			for j, x := range rv.ExtValues {
+				if j < len(r.ExtValues) {
+					r.ExtValues[j].Value = strings.TrimSpace(x.Value.XML)
+				}
+			}
+		}
+	}
 }
 
 func init() {
 	path := "epp > response > result"
 	scanResponse.MustHandleStartElement(path, func(c *xx.Context) error {
 		res := c.Value.(*Response)
-		res.Result.Code = c.AttrInt("", "code")
+		r := &res.Result
+		if r.Code != 0 {
+			r.Others = append(r.Others, Result{})
+			r = &r.Others[len(r.Others)-1]
+		}
+		r.Code = c.AttrInt("", "code")
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> msg", func(c *xx.Context) error {
+		c.Value.(*Response).currentResult().Lang = c.Attr("", "lang")
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+"> msg", func(c *xx.Context) error {
-		c.Value.(*Response).Result.Message = string(c.CharData)
+		c.Value.(*Response).currentResult().Message = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> value", func(c *xx.Context) error {
+		r := c.Value.(*Response).currentResult()
+		r.Values = append(r.Values, "")
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> extValue", func(c *xx.Context) error {
+		r := c.Value.(*Response).currentResult()
+		r.ExtValues = append(r.ExtValues, ExtValue{})
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+"> extValue > reason", func(c *xx.Context) error {
+		r := c.Value.(*Response).currentResult()
+		r.lastExtValue().Lang = c.Attr("", "lang")
 		return nil
 	})
 	scanResponse.MustHandleCharData(path+"> extValue > reason", func(c *xx.Context) error {
-		c.Value.(*Response).Result.Reason = string(c.CharData)
+		r := c.Value.(*Response).currentResult()
+		r.lastExtValue().Reason = string(c.CharData)
+		if r.Reason == "" {
+			r.Reason = string(c.CharData)
+		}
 		return nil
 	})
 }
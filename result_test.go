@@ -1,6 +1,8 @@
 package epp
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/nbio/st"
@@ -47,6 +49,107 @@ func TestScanResult(t *testing.T) {
 	st.Expect(t, r.IsFatal(), true)
 }
 
+func TestScanMultipleResults(t *testing.T) {
+	x := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="2004">
+      <msg lang="en">Parameter value range error</msg>
+      <value xmlns:obj="urn:ietf:params:xml:ns:obj">
+        <obj:elem1>2525</obj:elem1>
+      </value>
+    </result>
+    <result code="2005">
+      <msg>Parameter value syntax error</msg>
+      <value xmlns:obj="urn:ietf:params:xml:ns:obj">
+        <obj:elem2>ex(ample</obj:elem2>
+      </value>
+      <extValue>
+        <value xmlns:obj="urn:ietf:params:xml:ns:obj">
+          <obj:elem3>abc.ex(ample</obj:elem3>
+        </value>
+        <reason lang="fr">Caractère invalide</reason>
+      </extValue>
+    </result>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54321-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`)
+	res, err := parseResponse(x)
+	st.Assert(t, err, nil)
+	r := &res.Result
+	st.Expect(t, r.Code, 2004)
+	st.Expect(t, r.Message, "Parameter value range error")
+	st.Expect(t, r.Lang, "en")
+	st.Expect(t, r.Values, []string{`<obj:elem1>2525</obj:elem1>`})
+	st.Expect(t, len(r.ExtValues), 0)
+	st.Expect(t, r.ClTRID, "ABC-12345")
+
+	st.Assert(t, len(r.Others), 1)
+	o := r.Others[0]
+	st.Expect(t, o.Code, 2005)
+	st.Expect(t, o.Message, "Parameter value syntax error")
+	st.Expect(t, o.Values, []string{`<obj:elem2>ex(ample</obj:elem2>`})
+	st.Expect(t, o.ExtValues, []ExtValue{{Value: `<obj:elem3>abc.ex(ample</obj:elem3>`, Reason: "Caractère invalide", Lang: "fr"}})
+	st.Expect(t, o.Reason, "Caractère invalide")
+
+	// Further results are exposed through the error.
+	var err2 error = r
+	st.Expect(t, errors.Is(err2, ErrParameterRange), true)
+	st.Expect(t, errors.Is(err2, ErrParameterSyntax), true)
+	st.Expect(t, errors.Is(err2, ErrObjectExists), false)
+	st.Expect(t, err2.Error(), "EPP result code 2004: Parameter value range error; EPP result code 2005: Parameter value syntax error (Caractère invalide)")
+}
+
+func TestResultErrors(t *testing.T) {
+	var err error = &Result{Code: 2303, Message: "Domain not found"}
+	st.Expect(t, errors.Is(err, ErrObjectNotFound), true)
+	st.Expect(t, errors.Is(err, ErrObjectExists), false)
+	st.Expect(t, errors.Is(fmt.Errorf("info: %w", err), ErrObjectNotFound), true)
+	st.Expect(t, err.Error(), "EPP result code 2303: Domain not found")
+	st.Expect(t, ErrObjectNotFound.Error(), "EPP result code 2303: Object does not exist")
+
+	var r *Result
+	st.Expect(t, errors.As(fmt.Errorf("info: %w", err), &r), true)
+	st.Expect(t, r.Code, CodeObjectNotFound)
+
+	st.Expect(t, (&Result{Code: 2303}).Error(), "EPP result code 2303: Object does not exist")
+}
+
+func TestResultClassification(t *testing.T) {
+	tests := []struct {
+		code                                int
+		retryable, auth, objectState, fatal bool
+	}{
+		{CodeSuccess, false, false, false, false},
+		{CodeSyntaxError, false, false, false, false},
+		{CodeNotRenewable, false, false, true, false},
+		{CodeAuthentication, false, true, false, false},
+		{CodeAuthorization, false, true, false, false},
+		{CodeInvalidAuthInfo, false, true, false, false},
+		{CodePendingTransfer, false, false, true, false},
+		{CodeObjectExists, false, false, true, false},
+		{CodeObjectNotFound, false, false, true, false},
+		{CodeStatusProhibits, false, false, true, false},
+		{CodeParameterPolicy, false, false, false, false},
+		{CodeCommandFailed, true, false, false, false},
+		{CodeCommandFailedClosing, true, false, false, true},
+		{CodeAuthenticationClosing, false, true, false, true},
+		{CodeSessionLimitExceeded, true, false, false, true},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", &Result{Code: tt.code})
+		st.Expect(t, IsRetryable(err), tt.retryable, tt.code)
+		st.Expect(t, IsAuthError(err), tt.auth, tt.code)
+		st.Expect(t, IsObjectStateError(err), tt.objectState, tt.code)
+		st.Expect(t, IsFatalError(err), tt.fatal, tt.code)
+	}
+	st.Expect(t, IsRetryable(errors.New("other")), false)
+	st.Expect(t, IsFatalError(nil), false)
+}
+
 func BenchmarkScanResult(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
@@ -0,0 +1,182 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// ErrOutcomeUnknown is returned by Pool.DoTransform when a command failed
+// in a way that may or may not have applied it, and the outcome could not
+// be verified.
+var ErrOutcomeUnknown = errors.New("epp: command outcome unknown")
+
+// RetryPolicy configures how Pool.DoRetry and Pool.DoTransform retry
+// commands that fail with a transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a command is sent.
+	// Defaults to 3.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. It doubles with each
+	// retry, up to MaxBackoff. Defaults are 1 second and 30 seconds.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// withDefaults returns rp with defaults for unset fields.
+func (rp RetryPolicy) withDefaults() RetryPolicy {
+	if rp.MaxAttempts <= 0 {
+		rp.MaxAttempts = 3
+	}
+	if rp.MinBackoff <= 0 {
+		rp.MinBackoff = time.Second
+	}
+	if rp.MaxBackoff < rp.MinBackoff {
+		rp.MaxBackoff = max(30*time.Second, rp.MinBackoff)
+	}
+	return rp
+}
+
+// IsTransient reports whether err is a failure that a retry may overcome:
+// a lost or broken connection, or an EPP result for which
+// Result.IsRetryable is true. Canceled or expired contexts, and errors
+// matching ErrOutcomeUnknown, are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrOutcomeUnknown) {
+		return false
+	}
+	var r *Result
+	if errors.As(err, &r) {
+		return r.IsRetryable()
+	}
+	return isTransportError(err)
+}
+
+// isTransportError reports whether err is a failure of the connection
+// rather than of the command: a lost, closed or broken connection, or a
+// network error such as a timeout.
+func isTransportError(err error) bool {
+	var ne net.Error
+	return errors.Is(err, ErrBroken) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.As(err, &ne)
+}
+
+// DoRetry is like Do, but retries f on a session from p if it fails with
+// a transient error (see IsTransient), backing off between attempts as set
+// by PoolConfig.Retry. Sessions broken by the failure are replaced, logging
+// in again. f should only send idempotent commands, such as check, info,
+// poll request or hello, which are safe to send more than once.
+func (p *Pool) DoRetry(ctx context.Context, f func(c *Conn) error) error {
+	return p.DoTransform(ctx, f, nil)
+}
+
+// Verify reports whether a command sent by Pool.DoTransform was applied,
+// usually by querying the object with an info command on c.
+type Verify func(ctx context.Context, c *Conn) (applied bool, err error)
+
+// DoTransform is like DoRetry, for f that sends a command that is not
+// idempotent, such as create, renew or transfer. After a transient error,
+// the command may have been applied although no response was received, so
+// before each retry verify is called on a session from p. If it reports
+// that the command was applied, DoTransform returns nil without sending
+// it again; any response data set by f is then missing. If verify fails,
+// DoTransform returns an error matching ErrOutcomeUnknown and the error
+// from f. A nil verify retries without verification.
+func (p *Pool) DoTransform(ctx context.Context, f func(c *Conn) error, verify Verify) error {
+	rp := p.cfg.Retry.withDefaults()
+	backoff := rp.MinBackoff
+	for attempt := 1; ; attempt++ {
+		err := p.Do(ctx, f)
+		if !IsTransient(err) || attempt >= rp.MaxAttempts {
+			return err
+		}
+
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		backoff = min(backoff*2, rp.MaxBackoff)
+
+		if verify != nil {
+			var applied bool
+			verr := p.Do(ctx, func(c *Conn) error {
+				var err error
+				applied, err = verify(ctx, c)
+				return err
+			})
+			if verr != nil {
+				return fmt.Errorf("%w: %w; verifying: %w", ErrOutcomeUnknown, err, verr)
+			}
+			if applied {
+				return nil
+			}
+		}
+	}
+}
+
+// VerifyDomainCreate returns a Verify for a domain create, which reports
+// whether domain exists and is sponsored by the logged-in client.
+func VerifyDomainCreate(domain string) Verify {
+	return func(ctx context.Context, c *Conn) (bool, error) {
+		info, err := c.DomainInfoContext(ctx, domain, nil)
+		if errors.Is(err, ErrObjectNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return info.ClID != "" && info.ClID == c.clientID(), nil
+	}
+}
+
+// VerifyDomainRenew returns a Verify for a domain renew, which reports
+// whether the expiry date of domain has moved past curExpDate, the date
+// sent with the renew.
+func VerifyDomainRenew(domain string, curExpDate time.Time) Verify {
+	return func(ctx context.Context, c *Conn) (bool, error) {
+		info, err := c.DomainInfoContext(ctx, domain, nil)
+		if err != nil {
+			return false, err
+		}
+		y, m, d := curExpDate.Date()
+		cur := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		y, m, d = info.ExDate.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(cur), nil
+	}
+}
+
+// VerifyDomainTransfer returns a Verify for a domain transfer request,
+// which reports whether domain is sponsored by the logged-in client,
+// or has a pending transfer requested by it.
+func VerifyDomainTransfer(domain string) Verify {
+	return func(ctx context.Context, c *Conn) (bool, error) {
+		clID := c.clientID()
+		info, err := c.DomainInfoContext(ctx, domain, nil)
+		if err != nil {
+			return false, err
+		}
+		if info.ClID == clID {
+			return true, nil
+		}
+		tr, err := c.TransferDomainContext(ctx, "query", domain, 0, "", "", nil)
+		if err != nil {
+			if IsTransient(err) {
+				return false, err
+			}
+			// No transfer to query.
+			return false, nil
+		}
+		return tr.Status == "pending" && tr.REID == clID, nil
+	}
+}
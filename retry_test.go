@@ -0,0 +1,183 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+)
+
+func testRetryPool(ls *localServer) *Pool {
+	cfg := testPoolConfig(ls)
+	cfg.Retry = RetryPolicy{MinBackoff: time.Millisecond}
+	return NewPool(cfg)
+}
+
+const testXMLDomainInfo = `<epp><response><result code="1000"><msg>Command completed successfully</msg></result><resData><domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:clID>%s</domain:clID><domain:exDate>%s</domain:exDate></domain:infData></resData></response></epp>`
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{nil, false},
+		{ErrBroken, true},
+		{&DesyncError{Length: 1}, true},
+		{io.EOF, true},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{ErrCommandFailed, true},
+		{ErrCommandFailedClosing, true},
+		{ErrObjectExists, false},
+		{ErrQuotaExceeded, false},
+		{context.Canceled, false},
+		{fmt.Errorf("%w: %w", ErrOutcomeUnknown, io.EOF), false},
+		{errors.New("other"), false},
+	}
+	for i, tt := range tests {
+		st.Expect(t, IsTransient(tt.err), tt.transient, i)
+	}
+}
+
+func TestPoolDoRetry(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var checks, logins atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		switch {
+		case strings.Contains(req, "<login>"):
+			logins.Add(1)
+		case strings.Contains(req, "<check>"):
+			if checks.Add(1) == 1 {
+				return ""
+			}
+		}
+		return testXMLResultOK
+	})
+
+	p := testRetryPool(ls)
+	defer p.Close()
+	err = p.DoRetry(context.Background(), func(c *Conn) error {
+		_, err := c.CheckDomain("example.com")
+		return err
+	})
+	st.Expect(t, err, nil)
+	st.Expect(t, checks.Load(), int32(2))
+	st.Expect(t, logins.Load(), int32(2))
+}
+
+func TestPoolDoRetryLimits(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	var infos, checks atomic.Int32
+	serveTestSessions(ls, func(req string) string {
+		switch {
+		case strings.Contains(req, "<info>"):
+			infos.Add(1)
+			return `<epp><response><result code="2303"><msg>Object does not exist</msg></result></response></epp>`
+		case strings.Contains(req, "<check>"):
+			checks.Add(1)
+			return `<epp><response><result code="2400"><msg>Command failed</msg></result></response></epp>`
+		}
+		return testXMLResultOK
+	})
+
+	p := testRetryPool(ls)
+	defer p.Close()
+	ctx := context.Background()
+
+	// Errors that are not transient are returned at once.
+	err = p.DoRetry(ctx, func(c *Conn) error {
+		_, err := c.DomainInfo("example.com", nil)
+		return err
+	})
+	st.Expect(t, errors.Is(err, ErrObjectNotFound), true)
+	st.Expect(t, infos.Load(), int32(1))
+
+	err = p.DoRetry(ctx, func(c *Conn) error {
+		_, err := c.CheckDomain("example.com")
+		return err
+	})
+	st.Expect(t, errors.Is(err, ErrCommandFailed), true)
+	st.Expect(t, checks.Load(), int32(3))
+}
+
+func TestPoolDoTransform(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    string // response to <info>, "%s" replaced by clID
+		creates int32
+		err     error
+	}{
+		{"applied", fmt.Sprintf(testXMLDomainInfo, "user", "2030-01-01T00:00:00Z"), 1, nil},
+		{"not found", `<epp><response><result code="2303"><msg>Object does not exist</msg></result></response></epp>`, 2, nil},
+		{"other sponsor", fmt.Sprintf(testXMLDomainInfo, "other", "2030-01-01T00:00:00Z"), 2, nil},
+		{"unverified", "", 1, ErrOutcomeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ls, err := newLocalServer()
+			st.Assert(t, err, nil)
+			defer ls.teardown()
+			var creates atomic.Int32
+			serveTestSessions(ls, func(req string) string {
+				switch {
+				case strings.Contains(req, "<info>"):
+					return tt.info
+				case strings.Contains(req, "<create>"):
+					if creates.Add(1) == 1 {
+						return ""
+					}
+				}
+				return testXMLResultOK
+			})
+
+			p := testRetryPool(ls)
+			defer p.Close()
+			err = p.DoTransform(context.Background(), func(c *Conn) error {
+				_, err := c.CreateDomain("example.com", 1, "y", "2fooBAR", "", nil, nil, nil)
+				return err
+			}, VerifyDomainCreate("example.com"))
+			if tt.err == nil {
+				st.Expect(t, err, nil)
+			} else {
+				st.Expect(t, errors.Is(err, tt.err), true)
+				st.Expect(t, IsTransient(err), false)
+			}
+			st.Expect(t, creates.Load(), tt.creates)
+		})
+	}
+}
+
+func TestVerifyDomainRenew(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	serveTestSessions(ls, func(req string) string {
+		return fmt.Sprintf(testXMLDomainInfo, "user", "2031-03-01T12:00:00Z")
+	})
+	p := NewPool(testPoolConfig(ls))
+	defer p.Close()
+
+	for _, tt := range []struct {
+		cur     time.Time
+		applied bool
+	}{
+		{time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2031, 3, 1, 0, 0, 0, 0, time.UTC), false},
+	} {
+		err = p.Do(context.Background(), func(c *Conn) error {
+			applied, err := VerifyDomainRenew("example.com", tt.cur)(context.Background(), c)
+			st.Expect(t, applied, tt.applied)
+			return err
+		})
+		st.Expect(t, err, nil)
+	}
+}
@@ -0,0 +1,288 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/nbio/xx"
+)
+
+// SecDNSData represents DNSSEC information for a domain.
+// https://tools.ietf.org/html/rfc5910
+type SecDNSData struct {
+	MaxSigLife int       // <secDNS:maxSigLife>, in seconds
+	DS         []DSData  // <secDNS:dsData>
+	Keys       []KeyData // <secDNS:keyData>
+}
+
+// DSData represents a DS record in a <secDNS:dsData> element.
+type DSData struct {
+	KeyTag     int      // <secDNS:keyTag>
+	Alg        int      // <secDNS:alg>
+	DigestType int      // <secDNS:digestType>
+	Digest     string   // <secDNS:digest>
+	KeyData    *KeyData // optional <secDNS:keyData>
+}
+
+// KeyData represents a DNSKEY record in a <secDNS:keyData> element.
+type KeyData struct {
+	Flags    int    // <secDNS:flags>
+	Protocol int    // <secDNS:protocol>
+	Alg      int    // <secDNS:alg>
+	PubKey   string // <secDNS:pubKey>
+}
+
+// SecDNSUpdate holds the DNSSEC changes of a domain update command.
+// https://tools.ietf.org/html/rfc5910#section-5.2.5
+type SecDNSUpdate struct {
+	Urgent     bool       // urgent="1": the server should apply the change immediately
+	RemAll     bool       // <secDNS:rem><secDNS:all>true</secDNS:all>: remove all DS or key data
+	Rem        SecDNSData // DS or key data to remove; MaxSigLife is ignored
+	Add        SecDNSData // DS or key data to add; MaxSigLife is ignored
+	MaxSigLife int        // <secDNS:chg><secDNS:maxSigLife>; zero leaves it unchanged
+}
+
+// hasRecords reports whether s holds any DS or key data.
+func (s *SecDNSData) hasRecords() bool {
+	return len(s.DS) > 0 || len(s.Keys) > 0
+}
+
+// validate returns a ValidationError if s mixes DS and key data or holds
+// an invalid record.
+func (s *SecDNSData) validate(field string) error {
+	if s.MaxSigLife < 0 {
+		return invalid(field+".MaxSigLife", "%d is negative", s.MaxSigLife)
+	}
+	if len(s.DS) > 0 && len(s.Keys) > 0 {
+		return invalid(field, "cannot mix DS and key data")
+	}
+	for _, ds := range s.DS {
+		if ds.KeyTag < 0 || ds.KeyTag > 65535 {
+			return invalid(field+".DS", "key tag %d out of range", ds.KeyTag)
+		}
+		if ds.Alg < 0 || ds.Alg > 255 || ds.DigestType < 0 || ds.DigestType > 255 {
+			return invalid(field+".DS", "algorithm or digest type out of range for key tag %d", ds.KeyTag)
+		}
+		if _, err := hex.DecodeString(ds.Digest); err != nil || ds.Digest == "" {
+			return invalid(field+".DS", "digest %q is not hexadecimal", ds.Digest)
+		}
+		if ds.KeyData != nil {
+			if err := ds.KeyData.validate(field + ".DS.KeyData"); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range s.Keys {
+		if err := s.Keys[i].validate(field + ".Keys"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *KeyData) validate(field string) error {
+	if k.Flags < 0 || k.Flags > 65535 {
+		return invalid(field, "flags %d out of range", k.Flags)
+	}
+	if k.Protocol != 3 {
+		return invalid(field, "protocol %d is not 3", k.Protocol)
+	}
+	if k.Alg < 0 || k.Alg > 255 {
+		return invalid(field, "algorithm %d out of range", k.Alg)
+	}
+	if strings.TrimSpace(k.PubKey) == "" {
+		return invalid(field, "empty public key")
+	}
+	return nil
+}
+
+// validate returns a ValidationError if u changes nothing, combines RemAll
+// with records to remove, uses both the DS and key data interfaces, or
+// holds an invalid record.
+// https://tools.ietf.org/html/rfc5910#section-5.2.5
+func (u *SecDNSUpdate) validate(field string) error {
+	if !u.RemAll && u.MaxSigLife == 0 && !u.Rem.hasRecords() && !u.Add.hasRecords() {
+		return invalid(field, "no DS or key data to remove or add, and no maxSigLife change")
+	}
+	if u.RemAll && u.Rem.hasRecords() {
+		return invalid(field+".Rem", "cannot combine RemAll with DS or key data")
+	}
+	if u.MaxSigLife < 0 {
+		return invalid(field+".MaxSigLife", "%d is negative", u.MaxSigLife)
+	}
+	if err := u.Rem.validate(field + ".Rem"); err != nil {
+		return err
+	}
+	if err := u.Add.validate(field + ".Add"); err != nil {
+		return err
+	}
+	if len(u.Rem.DS) > 0 && len(u.Add.Keys) > 0 || len(u.Rem.Keys) > 0 && len(u.Add.DS) > 0 {
+		return invalid(field, "cannot mix DS and key data")
+	}
+	return nil
+}
+
+// encodeSecDNSCreate writes a <secDNS:create> element for s.
+func encodeSecDNSCreate(buf *bytes.Buffer, s *SecDNSData) {
+	buf.WriteString(`<secDNS:create xmlns:secDNS="`)
+	buf.WriteString(ExtSecDNS)
+	buf.WriteString(`">`)
+	if s.MaxSigLife > 0 {
+		buf.WriteString(`<secDNS:maxSigLife>`)
+		buf.WriteString(xmlInt(s.MaxSigLife))
+		buf.WriteString(`</secDNS:maxSigLife>`)
+	}
+	encodeSecDNSRecords(buf, s)
+	buf.WriteString(`</secDNS:create>`)
+}
+
+// encodeSecDNSUpdate writes a <secDNS:update> element for u.
+func encodeSecDNSUpdate(buf *bytes.Buffer, u *SecDNSUpdate) {
+	buf.WriteString(`<secDNS:update xmlns:secDNS="`)
+	buf.WriteString(ExtSecDNS)
+	buf.WriteString(`"`)
+	if u.Urgent {
+		buf.WriteString(` urgent="1"`)
+	}
+	buf.WriteString(`>`)
+	if u.RemAll {
+		buf.WriteString(`<secDNS:rem><secDNS:all>true</secDNS:all></secDNS:rem>`)
+	} else if u.Rem.hasRecords() {
+		buf.WriteString(`<secDNS:rem>`)
+		encodeSecDNSRecords(buf, &u.Rem)
+		buf.WriteString(`</secDNS:rem>`)
+	}
+	if u.Add.hasRecords() {
+		buf.WriteString(`<secDNS:add>`)
+		encodeSecDNSRecords(buf, &u.Add)
+		buf.WriteString(`</secDNS:add>`)
+	}
+	if u.MaxSigLife > 0 {
+		buf.WriteString(`<secDNS:chg><secDNS:maxSigLife>`)
+		buf.WriteString(xmlInt(u.MaxSigLife))
+		buf.WriteString(`</secDNS:maxSigLife></secDNS:chg>`)
+	}
+	buf.WriteString(`</secDNS:update>`)
+}
+
+// encodeSecDNSRecords writes the DS and key data in s.
+func encodeSecDNSRecords(buf *bytes.Buffer, s *SecDNSData) {
+	for i := range s.DS {
+		ds := &s.DS[i]
+		buf.WriteString(`<secDNS:dsData><secDNS:keyTag>`)
+		buf.WriteString(xmlInt(ds.KeyTag))
+		buf.WriteString(`</secDNS:keyTag><secDNS:alg>`)
+		buf.WriteString(xmlInt(ds.Alg))
+		buf.WriteString(`</secDNS:alg><secDNS:digestType>`)
+		buf.WriteString(xmlInt(ds.DigestType))
+		buf.WriteString(`</secDNS:digestType><secDNS:digest>`)
+		xml.EscapeText(buf, []byte(ds.Digest))
+		buf.WriteString(`</secDNS:digest>`)
+		if ds.KeyData != nil {
+			encodeKeyData(buf, ds.KeyData)
+		}
+		buf.WriteString(`</secDNS:dsData>`)
+	}
+	for i := range s.Keys {
+		encodeKeyData(buf, &s.Keys[i])
+	}
+}
+
+func encodeKeyData(buf *bytes.Buffer, k *KeyData) {
+	buf.WriteString(`<secDNS:keyData><secDNS:flags>`)
+	buf.WriteString(xmlInt(k.Flags))
+	buf.WriteString(`</secDNS:flags><secDNS:protocol>`)
+	buf.WriteString(xmlInt(k.Protocol))
+	buf.WriteString(`</secDNS:protocol><secDNS:alg>`)
+	buf.WriteString(xmlInt(k.Alg))
+	buf.WriteString(`</secDNS:alg><secDNS:pubKey>`)
+	xml.EscapeText(buf, []byte(k.PubKey))
+	buf.WriteString(`</secDNS:pubKey></secDNS:keyData>`)
+}
+
+func init() {
+	path := "epp > response > extension > " + ExtSecDNS + " infData"
+	secDNS := func(c *xx.Context) *SecDNSData {
+		return &c.Value.(*Response).DomainInfoResponse.SecDNS
+	}
+	lastDS := func(c *xx.Context) *DSData {
+		ds := secDNS(c).DS
+		return &ds[len(ds)-1]
+	}
+	lastKey := func(c *xx.Context) *KeyData {
+		keys := secDNS(c).Keys
+		return &keys[len(keys)-1]
+	}
+	atoi := func(c *xx.Context) (int, error) {
+		return strconv.Atoi(string(c.CharData))
+	}
+	scanResponse.MustHandleCharData(path+">maxSigLife", func(c *xx.Context) (err error) {
+		secDNS(c).MaxSigLife, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleStartElement(path+">dsData", func(c *xx.Context) error {
+		s := secDNS(c)
+		s.DS = append(s.DS, DSData{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">dsData>keyTag", func(c *xx.Context) (err error) {
+		lastDS(c).KeyTag, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>alg", func(c *xx.Context) (err error) {
+		lastDS(c).Alg, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>digestType", func(c *xx.Context) (err error) {
+		lastDS(c).DigestType, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>digest", func(c *xx.Context) error {
+		lastDS(c).Digest = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">dsData>keyData", func(c *xx.Context) error {
+		lastDS(c).KeyData = &KeyData{}
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">dsData>keyData>flags", func(c *xx.Context) (err error) {
+		lastDS(c).KeyData.Flags, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>keyData>protocol", func(c *xx.Context) (err error) {
+		lastDS(c).KeyData.Protocol, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>keyData>alg", func(c *xx.Context) (err error) {
+		lastDS(c).KeyData.Alg, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">dsData>keyData>pubKey", func(c *xx.Context) error {
+		lastDS(c).KeyData.PubKey = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleStartElement(path+">keyData", func(c *xx.Context) error {
+		s := secDNS(c)
+		s.Keys = append(s.Keys, KeyData{})
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">keyData>flags", func(c *xx.Context) (err error) {
+		lastKey(c).Flags, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">keyData>protocol", func(c *xx.Context) (err error) {
+		lastKey(c).Protocol, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">keyData>alg", func(c *xx.Context) (err error) {
+		lastKey(c).Alg, err = atoi(c)
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">keyData>pubKey", func(c *xx.Context) error {
+		lastKey(c).PubKey = string(c.CharData)
+		return nil
+	})
+}
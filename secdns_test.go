@@ -0,0 +1,120 @@
+package epp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestDomainCreateSecDNS(t *testing.T) {
+	dc := &DomainCreate{
+		Domain: "example.com",
+		SecDNS: &SecDNSData{
+			MaxSigLife: 604800,
+			DS:         []DSData{{KeyTag: 12345, Alg: 3, DigestType: 1, Digest: "49FD46E6C4B45C55D4AC"}},
+		},
+	}
+	x, err := dc.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create><extension><secDNS:create xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1"><secDNS:maxSigLife>604800</secDNS:maxSigLife><secDNS:dsData><secDNS:keyTag>12345</secDNS:keyTag><secDNS:alg>3</secDNS:alg><secDNS:digestType>1</secDNS:digestType><secDNS:digest>49FD46E6C4B45C55D4AC</secDNS:digest></secDNS:dsData></secDNS:create></extension></command></epp>`)
+}
+
+func TestDomainCreateSecDNSKeyData(t *testing.T) {
+	dc := &DomainCreate{
+		Domain: "example.com",
+		SecDNS: &SecDNSData{
+			Keys: []KeyData{{Flags: 257, Protocol: 3, Alg: 1, PubKey: "AQPJ////4Q=="}},
+		},
+	}
+	x, err := dc.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:create></create><extension><secDNS:create xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1"><secDNS:keyData><secDNS:flags>257</secDNS:flags><secDNS:protocol>3</secDNS:protocol><secDNS:alg>1</secDNS:alg><secDNS:pubKey>AQPJ////4Q==</secDNS:pubKey></secDNS:keyData></secDNS:create></extension></command></epp>`)
+}
+
+func TestDomainUpdateSecDNS(t *testing.T) {
+	du := &DomainUpdate{
+		Domain: "example.com",
+		SecDNS: &SecDNSUpdate{
+			Urgent:     true,
+			Rem:        SecDNSData{DS: []DSData{{KeyTag: 12345, Alg: 3, DigestType: 1, Digest: "38EC35D5B3A34B33C99B"}}},
+			Add:        SecDNSData{DS: []DSData{{KeyTag: 12346, Alg: 3, DigestType: 1, Digest: "49FD46E6C4B45C55D4AC"}}},
+			MaxSigLife: 605900,
+		},
+	}
+	x, err := du.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:update></update><extension><secDNS:update xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1" urgent="1"><secDNS:rem><secDNS:dsData><secDNS:keyTag>12345</secDNS:keyTag><secDNS:alg>3</secDNS:alg><secDNS:digestType>1</secDNS:digestType><secDNS:digest>38EC35D5B3A34B33C99B</secDNS:digest></secDNS:dsData></secDNS:rem><secDNS:add><secDNS:dsData><secDNS:keyTag>12346</secDNS:keyTag><secDNS:alg>3</secDNS:alg><secDNS:digestType>1</secDNS:digestType><secDNS:digest>49FD46E6C4B45C55D4AC</secDNS:digest></secDNS:dsData></secDNS:add><secDNS:chg><secDNS:maxSigLife>605900</secDNS:maxSigLife></secDNS:chg></secDNS:update></extension></command></epp>`)
+
+	du.SecDNS = &SecDNSUpdate{RemAll: true}
+	x, err = du.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:update></update><extension><secDNS:update xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1"><secDNS:rem><secDNS:all>true</secDNS:all></secDNS:rem></secDNS:update></extension></command></epp>`)
+}
+
+func TestSecDNSValidate(t *testing.T) {
+	ds := DSData{KeyTag: 12345, Alg: 3, DigestType: 1, Digest: "49FD46E6C4B45C55D4AC"}
+	key := KeyData{Flags: 257, Protocol: 3, Alg: 1, PubKey: "AQPJ////4Q=="}
+	tests := []struct {
+		v     interface{ Validate() error }
+		field string
+	}{
+		{&DomainCreate{Domain: "example.com", SecDNS: &SecDNSData{DS: []DSData{ds}, Keys: []KeyData{key}}}, "SecDNS"},
+		{&DomainCreate{Domain: "example.com", SecDNS: &SecDNSData{DS: []DSData{{KeyTag: 70000, Digest: "AB"}}}}, "SecDNS.DS"},
+		{&DomainCreate{Domain: "example.com", SecDNS: &SecDNSData{DS: []DSData{{Digest: "not hex"}}}}, "SecDNS.DS"},
+		{&DomainCreate{Domain: "example.com", SecDNS: &SecDNSData{Keys: []KeyData{{Protocol: 2, PubKey: "AQ=="}}}}, "SecDNS.Keys"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{RemAll: true, Rem: SecDNSData{DS: []DSData{ds}}}}, "SecDNS.Rem"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{Add: SecDNSData{Keys: []KeyData{{Protocol: 3}}}}}, "SecDNS.Add.Keys"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{}}, "SecDNS"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{Rem: SecDNSData{DS: []DSData{ds}}, Add: SecDNSData{Keys: []KeyData{key}}}}, "SecDNS"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{Rem: SecDNSData{Keys: []KeyData{key}}, Add: SecDNSData{DS: []DSData{ds}}}}, "SecDNS"},
+		{&DomainUpdate{Domain: "example.com", SecDNS: &SecDNSUpdate{Urgent: true}}, "SecDNS"},
+	}
+	for _, tt := range tests {
+		err := tt.v.Validate()
+		var verr *ValidationError
+		st.Assert(t, errors.As(err, &verr), true)
+		st.Expect(t, verr.Field, tt.field)
+	}
+}
+
+func TestScanSecDNSKeyData(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1000">
+      <msg>Command completed successfully</msg>
+    </result>
+    <extension>
+      <secDNS:infData xmlns:secDNS="urn:ietf:params:xml:ns:secDNS-1.1">
+        <secDNS:keyData>
+          <secDNS:flags>257</secDNS:flags>
+          <secDNS:protocol>3</secDNS:protocol>
+          <secDNS:alg>5</secDNS:alg>
+          <secDNS:pubKey>AQPJ////4Q==</secDNS:pubKey>
+        </secDNS:keyData>
+        <secDNS:keyData>
+          <secDNS:flags>256</secDNS:flags>
+          <secDNS:protocol>3</secDNS:protocol>
+          <secDNS:alg>8</secDNS:alg>
+          <secDNS:pubKey>AwEAAa==</secDNS:pubKey>
+        </secDNS:keyData>
+      </secDNS:infData>
+    </extension>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	st.Expect(t, res.DomainInfoResponse.SecDNS, SecDNSData{
+		Keys: []KeyData{
+			{Flags: 257, Protocol: 3, Alg: 5, PubKey: "AQPJ////4Q=="},
+			{Flags: 256, Protocol: 3, Alg: 8, PubKey: "AwEAAa=="},
+		},
+	})
+}
@@ -2,24 +2,30 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 )
 
 // Login initializes an authenticated EPP session.
+// If the server supports the loginSec extension, passwords longer than 16
+// characters are sent in the extension, along with c.UserAgent. Security
+// events returned by the server are in Result.SecurityEvents.
 // https://tools.ietf.org/html/rfc5730#section-2.9.1.1
+// https://tools.ietf.org/html/rfc8807
 func (c *Conn) Login(user, password, newPassword string) (Result, error) {
-	err := c.writeLogin(user, password, newPassword)
-	if err != nil {
-		return Result{}, err
-	}
-	res, err := c.readResponse()
+	return c.LoginContext(context.Background(), user, password, newPassword)
+}
+
+// LoginContext is like Login, but honors cancellation and deadlines from ctx.
+func (c *Conn) LoginContext(ctx context.Context, user, password, newPassword string) (Result, error) {
+	res, err := c.login(ctx, user, password, newPassword)
 	if err != nil {
 		return Result{}, err
 	}
 	return res.Result, nil
 }
 
-func (c *Conn) writeLogin(user, password, newPassword string) error {
+func (c *Conn) login(ctx context.Context, user, password, newPassword string) (*Response, error) {
 	ver, lang := "1.0", "en"
 	if len(c.Greeting.Versions) > 0 {
 		ver = c.Greeting.Versions[0]
@@ -27,22 +33,62 @@ func (c *Conn) writeLogin(user, password, newPassword string) error {
 	if len(c.Greeting.Languages) > 0 {
 		lang = c.Greeting.Languages[0]
 	}
-	x, err := encodeLogin(user, password, newPassword, ver, lang, c.Greeting.Objects, c.Greeting.Extensions)
+	ua := c.UserAgent
+	if ua == nil {
+		ua = &defaultUserAgent
+	}
+	x, err := encodeLoginUserAgent(user, password, newPassword, ver, lang, c.Greeting.Objects, c.Greeting.Extensions, ua)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err == nil {
+		c.m.Lock()
+		c.clID = user
+		c.m.Unlock()
 	}
-	return c.writeRequest(x)
+	return res, err
+}
+
+// clientID returns the client identifier c is logged in as.
+func (c *Conn) clientID() string {
+	c.m.Lock()
+	defer c.m.Unlock()
+	return c.clID
 }
 
 func encodeLogin(user, password, newPassword, version, language string, objects, extensions []string) ([]byte, error) {
+	return encodeLoginUserAgent(user, password, newPassword, version, language, objects, extensions, nil)
+}
+
+// encodeLoginUserAgent is like encodeLogin. If extensions include loginSec,
+// it sends ua and any password longer than the base schema allows in the
+// loginSec extension.
+func encodeLoginUserAgent(user, password, newPassword, version, language string, objects, extensions []string, ua *UserAgent) ([]byte, error) {
+	loginSec := false
+	for _, ext := range extensions {
+		if ext == ExtLoginSec {
+			loginSec = true
+		}
+	}
+	pw, newPW := password, newPassword
+	if loginSec {
+		if longPassword(pw) {
+			pw = loginSecPassword
+		}
+		if longPassword(newPW) {
+			newPW = loginSecPassword
+		}
+	}
+
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<login><clID>`)
 	xml.EscapeText(buf, []byte(user))
 	buf.WriteString(`</clID><pw>`)
-	xml.EscapeText(buf, []byte(password))
-	if len(newPassword) > 0 {
+	xml.EscapeText(buf, []byte(pw))
+	if len(newPW) > 0 {
 		buf.WriteString(`</pw><newPW>`)
-		xml.EscapeText(buf, []byte(newPassword))
+		xml.EscapeText(buf, []byte(newPW))
 		buf.WriteString(`</newPW><options><version>`)
 	} else {
 		buf.WriteString(`</pw><options><version>`)
@@ -66,19 +112,27 @@ func encodeLogin(user, password, newPassword, version, language string, objects,
 		buf.WriteString(`</svcExtension>`)
 	}
 	buf.WriteString(`</svcs></login>`)
+	if loginSec && (ua != nil || pw != password || newPW != newPassword) {
+		encodeLoginSec(buf, ua, password, newPassword)
+	}
 	buf.WriteString(xmlCommandSuffix)
 	return buf.Bytes(), nil
 }
 
 // Logout sends a <logout> command to terminate an EPP session.
+// It returns the transaction identifiers of the response.
 // https://tools.ietf.org/html/rfc5730#section-2.9.1.2
-func (c *Conn) Logout() error {
-	err := c.writeRequest(xmlLogout)
+func (c *Conn) Logout() (*TransactionID, error) {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext is like Logout, but honors cancellation and deadlines from ctx.
+func (c *Conn) LogoutContext(ctx context.Context) (*TransactionID, error) {
+	res, err := c.request(ctx, xmlLogout)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.readResponse()
-	return err
+	return &res.Result.TransactionID, nil
 }
 
 var xmlLogout = []byte(xmlCommandPrefix + `<logout/>` + xmlCommandSuffix)
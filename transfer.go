@@ -2,6 +2,7 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"time"
 
@@ -11,15 +12,16 @@ import (
 // TransferDomain requests a transfer operation for a domain.
 // https://tools.ietf.org/html/rfc5731#section-3.2.4
 func (c *Conn) TransferDomain(op string, domain string, period int, unit string, auth string, extData map[string]string) (*DomainTransferResponse, error) {
+	return c.TransferDomainContext(context.Background(), op, domain, period, unit, auth, extData)
+}
+
+// TransferDomainContext is like TransferDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) TransferDomainContext(ctx context.Context, op string, domain string, period int, unit string, auth string, extData map[string]string) (*DomainTransferResponse, error) {
 	x, err := encodeDomainTransfer(&c.Greeting, op, domain, period, unit, auth, extData)
 	if err != nil {
 		return nil, err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
-		return nil, err
-	}
-	res, err := c.readResponse()
+	res, err := c.request(ctx, x)
 	if err != nil {
 		return nil, err
 	}
@@ -52,21 +54,15 @@ func encodeDomainTransfer(greeting *Greeting, op string, domain string, period i
 
 	buf.WriteString(`</domain:transfer></transfer>`)
 
-	// Extensions (e.g. fee)
-	if fee, ok := extData["fee:fee"]; ok {
+	// Extensions (e.g. fee, allocation token)
+	_, hasFee := extData["fee:fee"]
+	token := extData[AllocationTokenKey]
+	if hasFee || token != "" {
 		buf.WriteString(`<extension>`)
-		buf.WriteString(`<fee:transfer xmlns:fee="`)
-		buf.WriteString(ExtFee10)
-		buf.WriteString(`">`)
-		if currency, ok := extData["fee:currency"]; ok {
-			buf.WriteString(`<fee:currency>`)
-			buf.WriteString(currency)
-			buf.WriteString(`</fee:currency>`)
+		encodeFee(buf, greeting, "transfer", extData)
+		if token != "" {
+			encodeAllocationToken(buf, token)
 		}
-		buf.WriteString(`<fee:fee>`)
-		buf.WriteString(fee)
-		buf.WriteString(`</fee:fee>`)
-		buf.WriteString(`</fee:transfer>`)
 		buf.WriteString(`</extension>`)
 	}
 
@@ -83,6 +79,8 @@ type DomainTransferResponse struct {
 	ACID   string    // <domain:acID>
 	ACDate time.Time // <domain:acDate>
 	ExDate time.Time // <domain:exDate>
+	Fee    FeeData   // <fee:trnData>
+	TransactionID
 }
 
 func init() {
@@ -126,3 +124,91 @@ func init() {
 		return err
 	})
 }
+
+// TransferContact requests a transfer operation for a contact.
+// op is one of request, query, approve, reject or cancel.
+// https://tools.ietf.org/html/rfc5733#section-3.2.4
+func (c *Conn) TransferContact(op string, id string, auth string) (*ContactTransferResponse, error) {
+	return c.TransferContactContext(context.Background(), op, id, auth)
+}
+
+// TransferContactContext is like TransferContact, but honors cancellation and deadlines from ctx.
+func (c *Conn) TransferContactContext(ctx context.Context, op string, id string, auth string) (*ContactTransferResponse, error) {
+	x, err := encodeContactTransfer(op, id, auth)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.ContactTransferResponse, nil
+}
+
+func encodeContactTransfer(op string, id string, auth string) ([]byte, error) {
+	buf := bytes.NewBufferString(xmlCommandPrefix)
+	buf.WriteString(`<transfer op="`)
+	buf.WriteString(op)
+	buf.WriteString(`">`)
+	buf.WriteString(`<contact:transfer xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
+	buf.WriteString(`<contact:id>`)
+	xml.EscapeText(buf, []byte(id))
+	buf.WriteString(`</contact:id>`)
+
+	if auth != "" {
+		buf.WriteString(`<contact:authInfo><contact:pw>`)
+		xml.EscapeText(buf, []byte(auth))
+		buf.WriteString(`</contact:pw></contact:authInfo>`)
+	}
+
+	buf.WriteString(`</contact:transfer></transfer>`)
+	buf.WriteString(xmlCommandSuffix)
+	return buf.Bytes(), nil
+}
+
+// ContactTransferResponse represents an EPP response for a contact transfer request.
+type ContactTransferResponse struct {
+	ID     string    // <contact:id>
+	Status string    // <contact:trStatus>
+	REID   string    // <contact:reID>
+	REDate time.Time // <contact:reDate>
+	ACID   string    // <contact:acID>
+	ACDate time.Time // <contact:acDate>
+	TransactionID
+}
+
+func init() {
+	path := "epp > response > resData > " + ObjContact + " trnData"
+	scanResponse.MustHandleCharData(path+">id", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		ctr.ID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">trStatus", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		ctr.Status = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reID", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		ctr.REID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">reDate", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		var err error
+		ctr.REDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+	scanResponse.MustHandleCharData(path+">acID", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		ctr.ACID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+">acDate", func(c *xx.Context) error {
+		ctr := &c.Value.(*Response).ContactTransferResponse
+		var err error
+		ctr.ACDate, err = time.Parse(time.RFC3339, string(c.CharData))
+		return err
+	})
+}
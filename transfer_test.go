@@ -3,6 +3,7 @@ package epp
 import (
 	"encoding/xml"
 	"testing"
+	"time"
 
 	"github.com/nbio/st"
 )
@@ -34,3 +35,57 @@ func TestEncodeDomainTransferWithFee(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestEncodeContactTransfer(t *testing.T) {
+	x, err := encodeContactTransfer("request", "sh8013", "2fooBAR")
+	st.Expect(t, err, nil)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><contact:transfer xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id><contact:authInfo><contact:pw>2fooBAR</contact:pw></contact:authInfo></contact:transfer></transfer></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	x, err = encodeContactTransfer("query", "sh8013", "")
+	st.Expect(t, err, nil)
+	expected = `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="query"><contact:transfer xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>sh8013</contact:id></contact:transfer></transfer></command></epp>`
+	st.Expect(t, string(x), expected)
+
+	var v struct{}
+	err = xml.Unmarshal(x, &v)
+	st.Expect(t, err, nil)
+}
+
+func TestScanContactTransferResponse(t *testing.T) {
+	x := `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0">
+  <response>
+    <result code="1001">
+      <msg>Command completed successfully; action pending</msg>
+    </result>
+    <resData>
+      <contact:trnData xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">
+        <contact:id>sh8013</contact:id>
+        <contact:trStatus>pending</contact:trStatus>
+        <contact:reID>ClientX</contact:reID>
+        <contact:reDate>2000-06-08T22:00:00.0Z</contact:reDate>
+        <contact:acID>ClientY</contact:acID>
+        <contact:acDate>2000-06-13T22:00:00.0Z</contact:acDate>
+      </contact:trnData>
+    </resData>
+    <trID>
+      <clTRID>ABC-12345</clTRID>
+      <svTRID>54322-XYZ</svTRID>
+    </trID>
+  </response>
+</epp>`
+
+	var res Response
+	err := IgnoreEOF(scanResponse.Scan(decoder(x), &res))
+	st.Expect(t, err, nil)
+	ctr := &res.ContactTransferResponse
+	st.Expect(t, ctr.ID, "sh8013")
+	st.Expect(t, ctr.Status, "pending")
+	st.Expect(t, ctr.REID, "ClientX")
+	st.Expect(t, ctr.REDate, time.Date(2000, 6, 8, 22, 0, 0, 0, time.UTC))
+	st.Expect(t, ctr.ACID, "ClientY")
+	st.Expect(t, ctr.ACDate, time.Date(2000, 6, 13, 22, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,138 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/nbio/xx"
+)
+
+// TransactionID holds the client and server transaction identifiers
+// returned in the <trID> element of an EPP response.
+// https://tools.ietf.org/html/rfc5730#section-2.6
+type TransactionID struct {
+	ClTRID string // <clTRID>
+	SvTRID string // <svTRID>
+}
+
+// transactionIDKey is the context key for a caller-supplied clTRID.
+type transactionIDKey struct{}
+
+// contextTransactionID is a caller-supplied clTRID and the number of
+// commands that have used it.
+type contextTransactionID struct {
+	id   string
+	uses atomic.Uint64
+}
+
+// WithTransactionID returns a copy of ctx that carries clTRID.
+// The first command sent with the returned context uses clTRID instead of
+// a generated client transaction identifier. Later commands sent with it,
+// such as retries by Pool.DoRetry or the second check sent by
+// CheckDomainExtensions, use clTRID with a suffix "-2", "-3", and so on,
+// so that each command has a distinct clTRID.
+func WithTransactionID(ctx context.Context, clTRID string) context.Context {
+	return context.WithValue(ctx, transactionIDKey{}, &contextTransactionID{id: clTRID})
+}
+
+// TransactionIDFromContext returns the clTRID carried by ctx, if any.
+func TransactionIDFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(transactionIDKey{}).(*contextTransactionID)
+	if !ok || v.id == "" {
+		return "", false
+	}
+	return v.id, true
+}
+
+var (
+	trIDPrefix  = newTransactionIDPrefix()
+	trIDCounter atomic.Uint64
+)
+
+func newTransactionIDPrefix() string {
+	var b [6]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// DefaultTransactionID returns a new client transaction identifier,
+// unique within the process and unlikely to collide across processes.
+// It is used when Conn.NewTransactionID is nil.
+func DefaultTransactionID() string {
+	return trIDPrefix + "-" + strconv.FormatUint(trIDCounter.Add(1), 10)
+}
+
+// transactionID returns the clTRID to use for the next command sent with ctx.
+func (c *Conn) transactionID(ctx context.Context) string {
+	if v, ok := ctx.Value(transactionIDKey{}).(*contextTransactionID); ok && v.id != "" {
+		if n := v.uses.Add(1); n > 1 {
+			return v.id + "-" + strconv.FormatUint(n, 10)
+		}
+		return v.id
+	}
+	if c.NewTransactionID != nil {
+		return c.NewTransactionID()
+	}
+	return DefaultTransactionID()
+}
+
+var (
+	tagCommandEnd = []byte(`</command>`)
+	tagClTRID     = []byte(`<clTRID>`)
+)
+
+// insertTransactionID adds a <clTRID> element to the EPP command in x.
+// It returns x unchanged if x is not a command or already has a clTRID.
+func insertTransactionID(x []byte, clTRID string) []byte {
+	if !needsTransactionID(x) {
+		return x
+	}
+	i := bytes.LastIndex(x, tagCommandEnd)
+	buf := bytes.NewBuffer(make([]byte, 0, len(x)+len(clTRID)+17))
+	buf.Write(x[:i])
+	buf.Write(tagClTRID)
+	xml.EscapeText(buf, []byte(clTRID))
+	buf.WriteString(`</clTRID>`)
+	buf.Write(x[i:])
+	return buf.Bytes()
+}
+
+// needsTransactionID reports whether x is an EPP command without a clTRID.
+func needsTransactionID(x []byte) bool {
+	return bytes.Contains(x, tagCommandEnd) && !bytes.Contains(x, tagClTRID)
+}
+
+// requestTransactionID returns the value of the <clTRID> element in x, if any.
+func requestTransactionID(x []byte) string {
+	i := bytes.Index(x, tagClTRID)
+	if i < 0 {
+		return ""
+	}
+	v := x[i+len(tagClTRID):]
+	j := bytes.IndexByte(v, '<')
+	if j < 0 {
+		return ""
+	}
+	tok, err := xml.NewDecoder(bytes.NewReader(v[:j])).Token()
+	if cd, ok := tok.(xml.CharData); err == nil && ok {
+		return string(cd)
+	}
+	return string(v[:j])
+}
+
+func init() {
+	path := "epp > response > trID"
+	scanResponse.MustHandleCharData(path+"> clTRID", func(c *xx.Context) error {
+		c.Value.(*Response).Result.ClTRID = string(c.CharData)
+		return nil
+	})
+	scanResponse.MustHandleCharData(path+"> svTRID", func(c *xx.Context) error {
+		c.Value.(*Response).Result.SvTRID = string(c.CharData)
+		return nil
+	})
+}
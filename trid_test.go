@@ -0,0 +1,100 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestInsertTransactionID(t *testing.T) {
+	x := insertTransactionID(xmlLogout, "ABC-12345")
+	st.Expect(t, string(x), xmlCommandPrefix+`<logout/><clTRID>ABC-12345</clTRID>`+xmlCommandSuffix)
+
+	// Already has a clTRID
+	x = insertTransactionID(x, "DEF-67890")
+	st.Expect(t, strings.Count(string(x), "<clTRID>"), 1)
+	st.Expect(t, strings.Contains(string(x), "ABC-12345"), true)
+
+	// Not a command
+	x = insertTransactionID(xmlHello, "ABC-12345")
+	st.Expect(t, string(x), string(xmlHello))
+
+	// Escaped
+	x = insertTransactionID(xmlLogout, "A&B")
+	st.Expect(t, strings.Contains(string(x), "<clTRID>A&amp;B</clTRID>"), true)
+}
+
+func TestDefaultTransactionID(t *testing.T) {
+	a, b := DefaultTransactionID(), DefaultTransactionID()
+	st.Reject(t, a, b)
+	st.Expect(t, len(a) >= 3 && len(a) <= 64, true)
+}
+
+func TestScanTransactionID(t *testing.T) {
+	var res Response
+	d := decoder(`<epp><response><result code="1000"><msg>Command completed successfully</msg></result><trID><clTRID>ABC-12345</clTRID><svTRID>54321-XYZ</svTRID></trID></response></epp>`)
+	err := IgnoreEOF(scanResponse.Scan(d, &res))
+	st.Expect(t, err, nil)
+	res.setTransactionID()
+	st.Expect(t, res.Result.ClTRID, "ABC-12345")
+	st.Expect(t, res.Result.SvTRID, "54321-XYZ")
+	st.Expect(t, res.DomainInfoResponse.ClTRID, "ABC-12345")
+	st.Expect(t, res.PollResponse.SvTRID, "54321-XYZ")
+}
+
+func TestClientTransactionID(t *testing.T) {
+	ls, err := newLocalServer()
+	st.Assert(t, err, nil)
+	defer ls.teardown()
+	ls.buildup(func(ls *localServer, ln net.Listener) {
+		conn, err := ls.Accept()
+		st.Assert(t, err, nil)
+		defer conn.Close()
+		writeDataUnit(conn, []byte(testXMLGreeting))
+		for _, code := range []string{"1000", "2303", "1001"} {
+			x, err := readTestRequest(conn)
+			st.Expect(t, err, nil)
+			s := string(x)
+			i, j := strings.Index(s, "<clTRID>"), strings.Index(s, "</clTRID>")
+			st.Assert(t, i >= 0 && j > i, true)
+			clTRID := s[i+len("<clTRID>") : j]
+			writeDataUnit(conn, []byte(`<epp><response><result code="`+code+`"><msg>ok</msg></result><trID><clTRID>`+clTRID+`</clTRID><svTRID>SV-`+code+`</svTRID></trID></response></epp>`))
+		}
+	})
+	nc, err := net.Dial(ls.Listener.Addr().Network(), ls.Listener.Addr().String())
+	st.Assert(t, err, nil)
+	c, err := NewConn(nc)
+	st.Assert(t, err, nil)
+	c.NewTransactionID = func() string { return "GEN-1" }
+
+	res, err := c.DomainInfo("example.com", nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ClTRID, "GEN-1")
+	st.Expect(t, res.SvTRID, "SV-1000")
+
+	ctx := WithTransactionID(context.Background(), "CALLER-1")
+	_, err = c.DomainInfoContext(ctx, "example.com", nil)
+	var r *Result
+	st.Assert(t, errors.As(err, &r), true)
+	st.Expect(t, r.ClTRID, "CALLER-1")
+	st.Expect(t, r.SvTRID, "SV-2303")
+
+	// Later commands with the same context get a distinct clTRID.
+	res, err = c.DomainInfoContext(ctx, "example.com", nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, res.ClTRID, "CALLER-1-2")
+	id, ok := TransactionIDFromContext(ctx)
+	st.Expect(t, ok, true)
+	st.Expect(t, id, "CALLER-1")
+}
+
+func TestRequestTransactionID(t *testing.T) {
+	st.Expect(t, requestTransactionID([]byte(`<epp><command><hello/></command></epp>`)), "")
+	st.Expect(t, requestTransactionID([]byte(`<epp><command><clTRID>ABC-123</clTRID></command></epp>`)), "ABC-123")
+	st.Expect(t, requestTransactionID([]byte(`<epp><command><clTRID>a&amp;b</clTRID></command></epp>`)), "a&b")
+	st.Expect(t, requestTransactionID([]byte(`<epp><command><clTRID></clTRID></command></epp>`)), "")
+}
@@ -2,154 +2,469 @@ package epp
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"sort"
 )
 
 // UpdateDomain requests the update of a domain.
+// The add and rem maps may hold "ns" ([]string), "contacts" (map of type to ID)
+// and "status" (map of status to text). The chg map may hold "registrant" and "auth".
+// Unknown keys, values of the wrong type and contact types other than admin,
+// tech and billing return a ValidationError, and empty chg values are left
+// unchanged. Before DomainUpdate was added, these were silently skipped and
+// empty values were sent.
 // https://tools.ietf.org/html/rfc5731#section-3.2.5
 func (c *Conn) UpdateDomain(domain string, add, rem map[string]interface{}, chg map[string]string) error {
-	x, err := encodeDomainUpdate(&c.Greeting, domain, add, rem, chg)
+	return c.UpdateDomainContext(context.Background(), domain, add, rem, chg)
+}
+
+// UpdateDomainContext is like UpdateDomain, but honors cancellation and deadlines from ctx.
+func (c *Conn) UpdateDomainContext(ctx context.Context, domain string, add, rem map[string]interface{}, chg map[string]string) error {
+	du, err := newDomainUpdate(domain, add, rem, chg)
 	if err != nil {
 		return err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
+	_, err = c.DomainUpdateContext(ctx, du)
+	return err
+}
+
+// DomainUpdate holds the parameters of a domain update command.
+// https://tools.ietf.org/html/rfc5731#section-3.2.5
+type DomainUpdate struct {
+	Domain string // <domain:name>, required
+	Add    DomainAddRem
+	Rem    DomainAddRem
+	Chg    DomainChange
+	SecDNS *SecDNSUpdate // optional DNSSEC changes (RFC 5910)
+
+	// Launch, if set, makes this an update of a launch application (RFC 8334).
+	Launch *LaunchApplication
+
+	// Extensions holds extension data, keyed as for DomainCreate:
+	//   - "fee:fee" and "fee:currency": the fee to accept
+	Extensions map[string]string
+}
+
+// DomainAddRem holds the attributes added to or removed from a domain.
+type DomainAddRem struct {
+	Nameservers []string        // <domain:ns><domain:hostObj>
+	Contacts    []DomainContact // <domain:contact>
+	Status      []ObjectStatus  // <domain:status>; only client statuses
+}
+
+func (ar *DomainAddRem) empty() bool {
+	return len(ar.Nameservers) == 0 && len(ar.Contacts) == 0 && len(ar.Status) == 0
+}
+
+// DomainChange holds the domain attributes to change.
+// Empty fields are left unchanged.
+type DomainChange struct {
+	Registrant string // <domain:registrant>
+	AuthInfo   string // <domain:authInfo><domain:pw>
+}
+
+func (chg *DomainChange) empty() bool {
+	return chg.Registrant == "" && chg.AuthInfo == ""
+}
+
+// Validate returns a ValidationError if du is not a valid domain update command.
+func (du *DomainUpdate) Validate() error {
+	if err := validateRequired("Domain", du.Domain); err != nil {
 		return err
 	}
-	_, err = c.readResponse()
-	return err
+	for _, ar := range []struct {
+		field string
+		*DomainAddRem
+	}{{"Add", &du.Add}, {"Rem", &du.Rem}} {
+		if err := validateHostNames(ar.field+".Nameservers", ar.Nameservers); err != nil {
+			return err
+		}
+		if err := validateContacts(ar.field+".Contacts", ar.Contacts); err != nil {
+			return err
+		}
+		if err := validateStatus(ar.field+".Status", ar.Status); err != nil {
+			return err
+		}
+	}
+	if du.SecDNS != nil {
+		if err := du.SecDNS.validate("SecDNS"); err != nil {
+			return err
+		}
+	}
+	if du.Add.empty() && du.Rem.empty() && du.Chg.empty() && du.SecDNS == nil {
+		return invalid("Update", "nothing to add, remove or change")
+	}
+	if du.Launch != nil {
+		return du.Launch.validate(true)
+	}
+	return nil
+}
+
+// DomainUpdate requests the update of a domain described by du.
+// https://tools.ietf.org/html/rfc5731#section-3.2.5
+// The response holds any fees charged, from the fee extension.
+func (c *Conn) DomainUpdate(du *DomainUpdate) (*DomainUpdateResponse, error) {
+	return c.DomainUpdateContext(context.Background(), du)
+}
+
+// DomainUpdateContext is like DomainUpdate, but honors cancellation and deadlines from ctx.
+func (c *Conn) DomainUpdateContext(ctx context.Context, du *DomainUpdate) (*DomainUpdateResponse, error) {
+	x, err := du.encode(&c.Greeting)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.DomainUpdateResponse, nil
+}
+
+// newDomainUpdate converts the arguments of UpdateDomain to a DomainUpdate.
+func newDomainUpdate(domain string, add, rem map[string]interface{}, chg map[string]string) (*DomainUpdate, error) {
+	du := &DomainUpdate{Domain: domain}
+	for _, ar := range []struct {
+		field string
+		data  map[string]interface{}
+		dst   *DomainAddRem
+	}{{"Add", add, &du.Add}, {"Rem", rem, &du.Rem}} {
+		for k, v := range ar.data {
+			var ok bool
+			switch k {
+			case "ns":
+				ar.dst.Nameservers, ok = v.([]string)
+			case "contacts":
+				var contacts map[string]string
+				contacts, ok = v.(map[string]string)
+				ar.dst.Contacts = domainContacts(contacts)
+			case "status":
+				ar.dst.Status, ok = statusList(v)
+			}
+			if !ok {
+				return nil, invalid(ar.field, "unexpected %q value of type %T", k, v)
+			}
+		}
+	}
+	for k, v := range chg {
+		switch k {
+		case "registrant":
+			du.Chg.Registrant = v
+		case "auth":
+			du.Chg.AuthInfo = v
+		default:
+			return nil, invalid("Chg", "unexpected key %q", k)
+		}
+	}
+	return du, nil
+}
+
+// statusList converts a map of status to text to a slice sorted by status.
+func statusList(v interface{}) ([]ObjectStatus, bool) {
+	m, ok := v.(map[string]string)
+	if !ok {
+		return nil, false
+	}
+	status := make([]ObjectStatus, 0, len(m))
+	for s, txt := range m {
+		status = append(status, ObjectStatus{Status: s, Text: txt})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Status < status[j].Status })
+	return status, true
 }
 
 func encodeDomainUpdate(greeting *Greeting, domain string, add, rem map[string]interface{}, chg map[string]string) ([]byte, error) {
+	du, err := newDomainUpdate(domain, add, rem, chg)
+	if err != nil {
+		return nil, err
+	}
+	return du.encode(greeting)
+}
+
+func (du *DomainUpdate) encode(greeting *Greeting) ([]byte, error) {
+	if err := du.Validate(); err != nil {
+		return nil, err
+	}
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">`)
 	buf.WriteString(`<domain:name>`)
-	xml.EscapeText(buf, []byte(domain))
+	xml.EscapeText(buf, []byte(du.Domain))
 	buf.WriteString(`</domain:name>`)
 
-	if len(add) > 0 {
+	if !du.Add.empty() {
 		buf.WriteString(`<domain:add>`)
-		encodeDomainAddRem(buf, add)
+		encodeDomainAddRem(buf, &du.Add)
 		buf.WriteString(`</domain:add>`)
 	}
 
-	if len(rem) > 0 {
+	if !du.Rem.empty() {
 		buf.WriteString(`<domain:rem>`)
-		encodeDomainAddRem(buf, rem)
+		encodeDomainAddRem(buf, &du.Rem)
 		buf.WriteString(`</domain:rem>`)
 	}
 
-	if len(chg) > 0 {
+	if !du.Chg.empty() {
 		buf.WriteString(`<domain:chg>`)
-		if registrant, ok := chg["registrant"]; ok {
+		if du.Chg.Registrant != "" {
 			buf.WriteString(`<domain:registrant>`)
-			xml.EscapeText(buf, []byte(registrant))
+			xml.EscapeText(buf, []byte(du.Chg.Registrant))
 			buf.WriteString(`</domain:registrant>`)
 		}
-		if auth, ok := chg["auth"]; ok {
+		if du.Chg.AuthInfo != "" {
 			buf.WriteString(`<domain:authInfo><domain:pw>`)
-			xml.EscapeText(buf, []byte(auth))
+			xml.EscapeText(buf, []byte(du.Chg.AuthInfo))
 			buf.WriteString(`</domain:pw></domain:authInfo>`)
 		}
 		buf.WriteString(`</domain:chg>`)
 	}
 
 	buf.WriteString(`</domain:update></update>`)
+	_, hasFee := du.Extensions["fee:fee"]
+	if du.SecDNS != nil || du.Launch != nil || hasFee {
+		buf.WriteString(`<extension>`)
+		if du.SecDNS != nil {
+			encodeSecDNSUpdate(buf, du.SecDNS)
+		}
+		if du.Launch != nil {
+			encodeLaunchApplication(buf, "update", du.Launch)
+		}
+		encodeFee(buf, greeting, "update", du.Extensions)
+		buf.WriteString(`</extension>`)
+	}
 	buf.WriteString(xmlCommandSuffix)
 	return buf.Bytes(), nil
 }
 
-func encodeDomainAddRem(buf *bytes.Buffer, data map[string]interface{}) {
-	if ns, ok := data["ns"].([]string); ok && len(ns) > 0 {
+func encodeDomainAddRem(buf *bytes.Buffer, ar *DomainAddRem) {
+	if len(ar.Nameservers) > 0 {
 		buf.WriteString(`<domain:ns>`)
-		for _, host := range ns {
+		for _, host := range ar.Nameservers {
 			buf.WriteString(`<domain:hostObj>`)
 			xml.EscapeText(buf, []byte(host))
 			buf.WriteString(`</domain:hostObj>`)
 		}
 		buf.WriteString(`</domain:ns>`)
 	}
-	if contacts, ok := data["contacts"].(map[string]string); ok && len(contacts) > 0 {
-		for _, result := range []string{"admin", "tech", "billing"} {
-			if id, ok := contacts[result]; ok {
-				buf.WriteString(`<domain:contact type="`)
-				buf.WriteString(result)
-				buf.WriteString(`">`)
-				xml.EscapeText(buf, []byte(id))
-				buf.WriteString(`</domain:contact>`)
-			}
-		}
-	}
-	if status, ok := data["status"].(map[string]string); ok && len(status) > 0 {
-		for s, txt := range status {
-			buf.WriteString(`<domain:status s="`)
-			buf.WriteString(s)
-			buf.WriteString(`">`)
-			xml.EscapeText(buf, []byte(txt))
-			buf.WriteString(`</domain:status>`)
+	encodeDomainContacts(buf, ar.Contacts)
+	encodeStatus(buf, "domain", ar.Status)
+}
+
+// encodeStatus writes a <prefix:status> element for each status.
+func encodeStatus(buf *bytes.Buffer, prefix string, status []ObjectStatus) {
+	for _, s := range status {
+		buf.WriteString(`<` + prefix + `:status s="`)
+		xml.EscapeText(buf, []byte(s.Status))
+		buf.WriteString(`"`)
+		if s.Lang != "" {
+			buf.WriteString(` lang="`)
+			xml.EscapeText(buf, []byte(s.Lang))
+			buf.WriteString(`"`)
 		}
+		buf.WriteString(`>`)
+		xml.EscapeText(buf, []byte(s.Text))
+		buf.WriteString(`</` + prefix + `:status>`)
 	}
 }
 
 // UpdateContact requests the update of a contact.
+// The add and rem maps may hold "status" (map of status to text). The chg map
+// may hold "postal" (PostalInfo), "voice", "fax", "email" and "auth" (string).
+// Unknown keys and values of the wrong type return a ValidationError, and
+// empty chg values are left unchanged. Before ContactUpdate was added, these
+// were silently skipped and empty values were sent.
 // https://tools.ietf.org/html/rfc5733#section-3.2.5
 func (c *Conn) UpdateContact(id string, add, rem, chg map[string]interface{}) error {
-	x, err := encodeContactUpdate(&c.Greeting, id, add, rem, chg)
+	return c.UpdateContactContext(context.Background(), id, add, rem, chg)
+}
+
+// UpdateContactContext is like UpdateContact, but honors cancellation and deadlines from ctx.
+func (c *Conn) UpdateContactContext(ctx context.Context, id string, add, rem, chg map[string]interface{}) error {
+	cu, err := newContactUpdate(id, add, rem, chg)
 	if err != nil {
 		return err
 	}
-	err = c.writeRequest(x)
-	if err != nil {
+	_, err = c.ContactUpdateContext(ctx, cu)
+	return err
+}
+
+// ContactUpdate holds the parameters of a contact update command.
+// https://tools.ietf.org/html/rfc5733#section-3.2.5
+type ContactUpdate struct {
+	ID  string         // <contact:id>, required
+	Add []ObjectStatus // <contact:add><contact:status>; only client statuses
+	Rem []ObjectStatus // <contact:rem><contact:status>; only client statuses
+	Chg ContactChange
+}
+
+// ContactChange holds the contact attributes to change.
+// Empty fields are left unchanged.
+type ContactChange struct {
+	Postal   []PostalInfo // <contact:postalInfo>; at most one "int" and one "loc"
+	Voice    string       // <contact:voice>
+	Fax      string       // <contact:fax>
+	Email    string       // <contact:email>
+	AuthInfo string       // <contact:authInfo><contact:pw>
+	Disclose *Disclose    // <contact:disclose>
+}
+
+func (chg *ContactChange) empty() bool {
+	return len(chg.Postal) == 0 && chg.Voice == "" && chg.Fax == "" &&
+		chg.Email == "" && chg.AuthInfo == "" && chg.Disclose == nil
+}
+
+// Validate returns a ValidationError if cu is not a valid contact update command.
+func (cu *ContactUpdate) Validate() error {
+	if err := validateRequired("ID", cu.ID); err != nil {
 		return err
 	}
-	_, err = c.readResponse()
-	return err
+	if err := validateStatus("Add", cu.Add); err != nil {
+		return err
+	}
+	if err := validateStatus("Rem", cu.Rem); err != nil {
+		return err
+	}
+	if len(cu.Chg.Postal) > 2 {
+		return invalid("Chg.Postal", "%d postal infos, at most 2 allowed", len(cu.Chg.Postal))
+	}
+	seen := map[string]bool{}
+	for _, pi := range cu.Chg.Postal {
+		typ := pi.Type
+		if typ == "" {
+			typ = "int"
+		}
+		if typ != "int" && typ != "loc" {
+			return invalid("Chg.Postal", "type %q is not int or loc", pi.Type)
+		}
+		if seen[typ] {
+			return invalid("Chg.Postal", "duplicate %s postal info", typ)
+		}
+		seen[typ] = true
+	}
+	if len(cu.Add) == 0 && len(cu.Rem) == 0 && cu.Chg.empty() {
+		return invalid("Update", "nothing to add, remove or change")
+	}
+	return nil
+}
+
+// ContactUpdate requests the update of a contact described by cu.
+// It returns the transaction identifiers of the response.
+// https://tools.ietf.org/html/rfc5733#section-3.2.5
+func (c *Conn) ContactUpdate(cu *ContactUpdate) (*TransactionID, error) {
+	return c.ContactUpdateContext(context.Background(), cu)
+}
+
+// ContactUpdateContext is like ContactUpdate, but honors cancellation and deadlines from ctx.
+func (c *Conn) ContactUpdateContext(ctx context.Context, cu *ContactUpdate) (*TransactionID, error) {
+	x, err := cu.encode(&c.Greeting)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.request(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Result.TransactionID, nil
+}
+
+// newContactUpdate converts the arguments of UpdateContact to a ContactUpdate.
+func newContactUpdate(id string, add, rem, chg map[string]interface{}) (*ContactUpdate, error) {
+	cu := &ContactUpdate{ID: id}
+	for _, ar := range []struct {
+		field string
+		data  map[string]interface{}
+		dst   *[]ObjectStatus
+	}{{"Add", add, &cu.Add}, {"Rem", rem, &cu.Rem}} {
+		for k, v := range ar.data {
+			var ok bool
+			if k == "status" {
+				*ar.dst, ok = statusList(v)
+			}
+			if !ok {
+				return nil, invalid(ar.field, "unexpected %q value of type %T", k, v)
+			}
+		}
+	}
+	for k, v := range chg {
+		var ok bool
+		switch k {
+		case "postal":
+			var pi PostalInfo
+			pi, ok = v.(PostalInfo)
+			cu.Chg.Postal = []PostalInfo{pi}
+		case "voice":
+			cu.Chg.Voice, ok = v.(string)
+		case "fax":
+			cu.Chg.Fax, ok = v.(string)
+		case "email":
+			cu.Chg.Email, ok = v.(string)
+		case "auth":
+			cu.Chg.AuthInfo, ok = v.(string)
+		}
+		if !ok {
+			return nil, invalid("Chg", "unexpected %q value of type %T", k, v)
+		}
+	}
+	return cu, nil
 }
 
 func encodeContactUpdate(greeting *Greeting, id string, add, rem, chg map[string]interface{}) ([]byte, error) {
+	cu, err := newContactUpdate(id, add, rem, chg)
+	if err != nil {
+		return nil, err
+	}
+	return cu.encode(greeting)
+}
+
+func (cu *ContactUpdate) encode(greeting *Greeting) ([]byte, error) {
+	if err := cu.Validate(); err != nil {
+		return nil, err
+	}
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<update><contact:update xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">`)
 	buf.WriteString(`<contact:id>`)
-	xml.EscapeText(buf, []byte(id))
+	xml.EscapeText(buf, []byte(cu.ID))
 	buf.WriteString(`</contact:id>`)
 
-	if len(add) > 0 {
+	if len(cu.Add) > 0 {
 		buf.WriteString(`<contact:add>`)
-		encodeContactAddRem(buf, add)
+		encodeStatus(buf, "contact", cu.Add)
 		buf.WriteString(`</contact:add>`)
 	}
 
-	if len(rem) > 0 {
+	if len(cu.Rem) > 0 {
 		buf.WriteString(`<contact:rem>`)
-		encodeContactAddRem(buf, rem)
+		encodeStatus(buf, "contact", cu.Rem)
 		buf.WriteString(`</contact:rem>`)
 	}
 
-	if len(chg) > 0 {
+	if chg := &cu.Chg; !chg.empty() {
 		buf.WriteString(`<contact:chg>`)
-		if pi, ok := chg["postal"].(PostalInfo); ok {
-			encodePostalInfo(buf, &pi)
+		for i := range chg.Postal {
+			encodePostalInfo(buf, &chg.Postal[i])
 		}
-		if voice, ok := chg["voice"].(string); ok {
+		if chg.Voice != "" {
 			buf.WriteString(`<contact:voice>`)
-			xml.EscapeText(buf, []byte(voice))
+			xml.EscapeText(buf, []byte(chg.Voice))
 			buf.WriteString(`</contact:voice>`)
 		}
-		if fax, ok := chg["fax"].(string); ok {
+		if chg.Fax != "" {
 			buf.WriteString(`<contact:fax>`)
-			xml.EscapeText(buf, []byte(fax))
+			xml.EscapeText(buf, []byte(chg.Fax))
 			buf.WriteString(`</contact:fax>`)
 		}
-		if email, ok := chg["email"].(string); ok {
+		if chg.Email != "" {
 			buf.WriteString(`<contact:email>`)
-			xml.EscapeText(buf, []byte(email))
+			xml.EscapeText(buf, []byte(chg.Email))
 			buf.WriteString(`</contact:email>`)
 		}
-		if auth, ok := chg["auth"].(string); ok {
+		if chg.AuthInfo != "" {
 			buf.WriteString(`<contact:authInfo><contact:pw>`)
-			xml.EscapeText(buf, []byte(auth))
+			xml.EscapeText(buf, []byte(chg.AuthInfo))
 			buf.WriteString(`</contact:pw></contact:authInfo>`)
 		}
+		if chg.Disclose != nil {
+			encodeDisclose(buf, chg.Disclose)
+		}
 		buf.WriteString(`</contact:chg>`)
 	}
 
@@ -158,60 +473,160 @@ func encodeContactUpdate(greeting *Greeting, id string, add, rem, chg map[string
 	return buf.Bytes(), nil
 }
 
-func encodeContactAddRem(buf *bytes.Buffer, data map[string]interface{}) {
-	if status, ok := data["status"].(map[string]string); ok && len(status) > 0 {
-		for s, txt := range status {
-			buf.WriteString(`<contact:status s="`)
-			buf.WriteString(s)
-			buf.WriteString(`">`)
-			xml.EscapeText(buf, []byte(txt))
-			buf.WriteString(`</contact:status>`)
+// UpdateHost requests the update of a host.
+// The add and rem maps may hold "ips" and "v6" ([]string) and "status"
+// (map of status to text). The chg map may hold "name" (string).
+// Unknown keys and values of the wrong type return a ValidationError;
+// before HostUpdate was added, they were silently skipped.
+// https://tools.ietf.org/html/rfc5732#section-3.2.5
+func (c *Conn) UpdateHost(host string, add, rem, chg map[string]interface{}) error {
+	return c.UpdateHostContext(context.Background(), host, add, rem, chg)
+}
+
+// UpdateHostContext is like UpdateHost, but honors cancellation and deadlines from ctx.
+func (c *Conn) UpdateHostContext(ctx context.Context, host string, add, rem, chg map[string]interface{}) error {
+	hu, err := newHostUpdate(host, add, rem, chg)
+	if err != nil {
+		return err
+	}
+	_, err = c.HostUpdateContext(ctx, hu)
+	return err
+}
+
+// HostUpdate holds the parameters of a host update command.
+// https://tools.ietf.org/html/rfc5732#section-3.2.5
+type HostUpdate struct {
+	Host    string // <host:name>, required
+	Add     HostAddRem
+	Rem     HostAddRem
+	NewName string // <host:chg><host:name>
+}
+
+// HostAddRem holds the attributes added to or removed from a host.
+type HostAddRem struct {
+	IPv4   []string       // <host:addr ip="v4">
+	IPv6   []string       // <host:addr ip="v6">
+	Status []ObjectStatus // <host:status>; only client statuses
+}
+
+func (ar *HostAddRem) empty() bool {
+	return len(ar.IPv4) == 0 && len(ar.IPv6) == 0 && len(ar.Status) == 0
+}
+
+// Validate returns a ValidationError if hu is not a valid host update command.
+func (hu *HostUpdate) Validate() error {
+	if err := validateRequired("Host", hu.Host); err != nil {
+		return err
+	}
+	for _, ar := range []struct {
+		field string
+		*HostAddRem
+	}{{"Add", &hu.Add}, {"Rem", &hu.Rem}} {
+		if err := validateAddrs(ar.field+".IPv4", "v4", ar.IPv4); err != nil {
+			return err
 		}
+		if err := validateAddrs(ar.field+".IPv6", "v6", ar.IPv6); err != nil {
+			return err
+		}
+		if err := validateStatus(ar.field+".Status", ar.Status); err != nil {
+			return err
+		}
+	}
+	if hu.Add.empty() && hu.Rem.empty() && hu.NewName == "" {
+		return invalid("Update", "nothing to add, remove or change")
 	}
+	return nil
 }
 
-// UpdateHost requests the update of a host.
+// HostUpdate requests the update of a host described by hu.
+// It returns the transaction identifiers of the response.
 // https://tools.ietf.org/html/rfc5732#section-3.2.5
-func (c *Conn) UpdateHost(host string, add, rem, chg map[string]interface{}) error {
-	x, err := encodeHostUpdate(&c.Greeting, host, add, rem, chg)
+func (c *Conn) HostUpdate(hu *HostUpdate) (*TransactionID, error) {
+	return c.HostUpdateContext(context.Background(), hu)
+}
+
+// HostUpdateContext is like HostUpdate, but honors cancellation and deadlines from ctx.
+func (c *Conn) HostUpdateContext(ctx context.Context, hu *HostUpdate) (*TransactionID, error) {
+	x, err := hu.encode(&c.Greeting)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = c.writeRequest(x)
+	res, err := c.request(ctx, x)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = c.readResponse()
-	return err
+	return &res.Result.TransactionID, nil
+}
+
+// newHostUpdate converts the arguments of UpdateHost to a HostUpdate.
+func newHostUpdate(host string, add, rem, chg map[string]interface{}) (*HostUpdate, error) {
+	hu := &HostUpdate{Host: host}
+	for _, ar := range []struct {
+		field string
+		data  map[string]interface{}
+		dst   *HostAddRem
+	}{{"Add", add, &hu.Add}, {"Rem", rem, &hu.Rem}} {
+		for k, v := range ar.data {
+			var ok bool
+			switch k {
+			case "ips":
+				ar.dst.IPv4, ok = v.([]string)
+			case "v6":
+				ar.dst.IPv6, ok = v.([]string)
+			case "status":
+				ar.dst.Status, ok = statusList(v)
+			}
+			if !ok {
+				return nil, invalid(ar.field, "unexpected %q value of type %T", k, v)
+			}
+		}
+	}
+	for k, v := range chg {
+		var ok bool
+		if k == "name" {
+			hu.NewName, ok = v.(string)
+		}
+		if !ok {
+			return nil, invalid("Chg", "unexpected %q value of type %T", k, v)
+		}
+	}
+	return hu, nil
 }
 
 func encodeHostUpdate(greeting *Greeting, host string, add, rem, chg map[string]interface{}) ([]byte, error) {
+	hu, err := newHostUpdate(host, add, rem, chg)
+	if err != nil {
+		return nil, err
+	}
+	return hu.encode(greeting)
+}
+
+func (hu *HostUpdate) encode(greeting *Greeting) ([]byte, error) {
+	if err := hu.Validate(); err != nil {
+		return nil, err
+	}
 	buf := bytes.NewBufferString(xmlCommandPrefix)
 	buf.WriteString(`<update><host:update xmlns:host="urn:ietf:params:xml:ns:host-1.0">`)
 	buf.WriteString(`<host:name>`)
-	xml.EscapeText(buf, []byte(host))
+	xml.EscapeText(buf, []byte(hu.Host))
 	buf.WriteString(`</host:name>`)
 
-	if len(add) > 0 {
+	if !hu.Add.empty() {
 		buf.WriteString(`<host:add>`)
-		encodeHostAddRem(buf, add)
+		encodeHostAddRem(buf, &hu.Add)
 		buf.WriteString(`</host:add>`)
 	}
 
-	if len(rem) > 0 {
+	if !hu.Rem.empty() {
 		buf.WriteString(`<host:rem>`)
-		encodeHostAddRem(buf, rem)
+		encodeHostAddRem(buf, &hu.Rem)
 		buf.WriteString(`</host:rem>`)
 	}
 
-	if len(chg) > 0 {
-		buf.WriteString(`<host:chg>`)
-		if newName, ok := chg["name"].(string); ok {
-			buf.WriteString(`<host:name>`)
-			xml.EscapeText(buf, []byte(newName))
-			buf.WriteString(`</host:name>`)
-		}
-		buf.WriteString(`</host:chg>`)
+	if hu.NewName != "" {
+		buf.WriteString(`<host:chg><host:name>`)
+		xml.EscapeText(buf, []byte(hu.NewName))
+		buf.WriteString(`</host:name></host:chg>`)
 	}
 
 	buf.WriteString(`</host:update></update>`)
@@ -219,28 +634,16 @@ func encodeHostUpdate(greeting *Greeting, host string, add, rem, chg map[string]
 	return buf.Bytes(), nil
 }
 
-func encodeHostAddRem(buf *bytes.Buffer, data map[string]interface{}) {
-	if ips, ok := data["ips"].([]string); ok {
-		for _, ip := range ips {
-			buf.WriteString(`<host:addr ip="v4">`)
-			xml.EscapeText(buf, []byte(ip))
-			buf.WriteString(`</host:addr>`)
-		}
-	}
-	if v6, ok := data["v6"].([]string); ok {
-		for _, ip := range v6 {
-			buf.WriteString(`<host:addr ip="v6">`)
-			xml.EscapeText(buf, []byte(ip))
-			buf.WriteString(`</host:addr>`)
-		}
+func encodeHostAddRem(buf *bytes.Buffer, ar *HostAddRem) {
+	for _, ip := range ar.IPv4 {
+		buf.WriteString(`<host:addr ip="v4">`)
+		xml.EscapeText(buf, []byte(ip))
+		buf.WriteString(`</host:addr>`)
 	}
-	if status, ok := data["status"].(map[string]string); ok && len(status) > 0 {
-		for s, txt := range status {
-			buf.WriteString(`<host:status s="`)
-			buf.WriteString(s)
-			buf.WriteString(`">`)
-			xml.EscapeText(buf, []byte(txt))
-			buf.WriteString(`</host:status>`)
-		}
+	for _, ip := range ar.IPv6 {
+		buf.WriteString(`<host:addr ip="v6">`)
+		xml.EscapeText(buf, []byte(ip))
+		buf.WriteString(`</host:addr>`)
 	}
+	encodeStatus(buf, "host", ar.Status)
 }
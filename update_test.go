@@ -2,6 +2,7 @@ package epp
 
 import (
 	"encoding/xml"
+	"errors"
 	"testing"
 
 	"github.com/nbio/st"
@@ -75,3 +76,123 @@ func TestEncodeHostUpdate(t *testing.T) {
 	err = xml.Unmarshal(x, &v)
 	st.Expect(t, err, nil)
 }
+
+func TestDomainUpdateEncode(t *testing.T) {
+	du := &DomainUpdate{
+		Domain: "example.com",
+		Add: DomainAddRem{
+			Nameservers: []string{"ns1.example.com"},
+			Contacts:    []DomainContact{{Type: "admin", ID: "admin123"}},
+			Status:      []ObjectStatus{{Status: "clientHold", Lang: "en", Text: "Payment overdue"}},
+		},
+		Rem: DomainAddRem{
+			Status: []ObjectStatus{{Status: "clientUpdateProhibited"}},
+		},
+		Chg: DomainChange{AuthInfo: "newAuth"},
+	}
+	x, err := du.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:add><domain:ns><domain:hostObj>ns1.example.com</domain:hostObj></domain:ns><domain:contact type="admin">admin123</domain:contact><domain:status s="clientHold" lang="en">Payment overdue</domain:status></domain:add><domain:rem><domain:status s="clientUpdateProhibited"></domain:status></domain:rem><domain:chg><domain:authInfo><domain:pw>newAuth</domain:pw></domain:authInfo></domain:chg></domain:update></update></command></epp>`)
+}
+
+func TestEncodeDomainUpdateStatusOrder(t *testing.T) {
+	add := map[string]interface{}{
+		"status": map[string]string{"clientTransferProhibited": "", "clientHold": "", "clientDeleteProhibited": ""},
+	}
+	x, err := encodeDomainUpdate(nil, "example.com", add, nil, nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:add><domain:status s="clientDeleteProhibited"></domain:status><domain:status s="clientHold"></domain:status><domain:status s="clientTransferProhibited"></domain:status></domain:add></domain:update></update></command></epp>`)
+}
+
+func TestEncodeUpdateInvalidMapValues(t *testing.T) {
+	var verr *ValidationError
+
+	_, err := encodeDomainUpdate(nil, "example.com", map[string]interface{}{"ns": "ns1.example.com"}, nil, nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Add")
+
+	_, err = encodeDomainUpdate(nil, "example.com", nil, nil, map[string]string{"registrar": "r1"})
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Chg")
+
+	_, err = encodeContactUpdate(nil, "c1", nil, map[string]interface{}{"status": []string{"clientHold"}}, nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Rem")
+
+	_, err = encodeHostUpdate(nil, "ns1.example.com", nil, nil, map[string]interface{}{"name": 1})
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Chg")
+	_, err = encodeDomainUpdate(nil, "example.com", map[string]interface{}{"contacts": map[string]string{"owner": "c1"}}, nil, nil)
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Add.Contacts")
+}
+
+func TestUpdateValidateEmpty(t *testing.T) {
+	for _, v := range []interface{ Validate() error }{
+		&DomainUpdate{Domain: "example.com"},
+		&DomainUpdate{Domain: "example.com", Extensions: map[string]string{"fee:fee": "5.00"}},
+		&ContactUpdate{ID: "c1"},
+		&HostUpdate{Host: "ns1.example.com"},
+	} {
+		var verr *ValidationError
+		st.Assert(t, errors.As(v.Validate(), &verr), true)
+		st.Expect(t, verr.Field, "Update")
+	}
+	_, err := encodeDomainUpdate(nil, "example.com", nil, nil, map[string]string{"auth": ""})
+	var verr *ValidationError
+	st.Assert(t, errors.As(err, &verr), true)
+	st.Expect(t, verr.Field, "Update")
+}
+
+func TestContactUpdateEncode(t *testing.T) {
+	cu := &ContactUpdate{
+		ID:  "contact123",
+		Add: []ObjectStatus{{Status: "clientDeleteProhibited"}},
+		Chg: ContactChange{
+			Email:    "new@example.com",
+			Disclose: &Disclose{Flag: false, Name: []string{"int"}, Voice: true},
+		},
+	}
+	x, err := cu.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><contact:update xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:id>contact123</contact:id><contact:add><contact:status s="clientDeleteProhibited"></contact:status></contact:add><contact:chg><contact:email>new@example.com</contact:email><contact:disclose flag="0"><contact:name type="int"/><contact:voice/></contact:disclose></contact:chg></contact:update></update></command></epp>`)
+}
+
+func TestHostUpdateEncode(t *testing.T) {
+	hu := &HostUpdate{
+		Host:    "ns1.example.com",
+		Add:     HostAddRem{IPv4: []string{"192.0.2.1"}, IPv6: []string{"2001:db8::1"}},
+		Rem:     HostAddRem{Status: []ObjectStatus{{Status: "clientUpdateProhibited"}}},
+		NewName: "ns2.example.com",
+	}
+	x, err := hu.encode(nil)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(x), `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><host:update xmlns:host="urn:ietf:params:xml:ns:host-1.0"><host:name>ns1.example.com</host:name><host:add><host:addr ip="v4">192.0.2.1</host:addr><host:addr ip="v6">2001:db8::1</host:addr></host:add><host:rem><host:status s="clientUpdateProhibited"></host:status></host:rem><host:chg><host:name>ns2.example.com</host:name></host:chg></host:update></update></command></epp>`)
+}
+
+func TestUpdateValidate(t *testing.T) {
+	tests := []struct {
+		v     interface{ Validate() error }
+		field string
+	}{
+		{&DomainUpdate{}, "Domain"},
+		{&DomainUpdate{Domain: "example.com", Add: DomainAddRem{Status: []ObjectStatus{{Status: "serverHold"}}}}, "Add.Status"},
+		{&DomainUpdate{Domain: "example.com", Rem: DomainAddRem{Contacts: []DomainContact{{Type: "registrant", ID: "c1"}}}}, "Rem.Contacts"},
+		{&ContactUpdate{}, "ID"},
+		{&ContactUpdate{ID: "c1", Chg: ContactChange{Postal: []PostalInfo{{Type: "int"}, {}}}}, "Chg.Postal"},
+		{&ContactUpdate{ID: "c1", Chg: ContactChange{Postal: []PostalInfo{{Type: "intl"}}}}, "Chg.Postal"},
+		{&HostUpdate{}, "Host"},
+		{&HostUpdate{Host: "ns1.example.com", Add: HostAddRem{IPv4: []string{"2001:db8::1"}}}, "Add.IPv4"},
+		{&HostUpdate{Host: "ns1.example.com", Rem: HostAddRem{IPv6: []string{"not-an-ip"}}}, "Rem.IPv6"},
+	}
+	for _, tt := range tests {
+		err := tt.v.Validate()
+		var verr *ValidationError
+		st.Assert(t, errors.As(err, &verr), true)
+		st.Expect(t, verr.Field, tt.field)
+	}
+}
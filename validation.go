@@ -0,0 +1,93 @@
+package epp
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ValidationError is returned when the parameters of a command are invalid.
+// The command is not sent to the server.
+type ValidationError struct {
+	Field   string // parameter name, e.g. "Add.Status"
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("epp: invalid %s: %s", e.Field, e.Message)
+}
+
+func invalid(field, format string, args ...interface{}) error {
+	return &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// validateRequired returns a ValidationError if v is empty.
+func validateRequired(field, v string) error {
+	if strings.TrimSpace(v) == "" {
+		return invalid(field, "required")
+	}
+	return nil
+}
+
+// validatePeriod checks a registration period and unit.
+// https://tools.ietf.org/html/rfc5731#section-2.4
+func validatePeriod(period int, unit string) error {
+	if period < 0 || period > 99 {
+		return invalid("Period", "%d is not between 0 and 99, 0 omits the period", period)
+	}
+	switch unit {
+	case "", "y", "m":
+	default:
+		return invalid("Unit", "%q is not y or m", unit)
+	}
+	return nil
+}
+
+// validateContacts checks the type and ID of each domain contact.
+func validateContacts(field string, contacts []DomainContact) error {
+	for _, contact := range contacts {
+		switch contact.Type {
+		case "admin", "billing", "tech":
+		default:
+			return invalid(field, "contact type %q is not admin, billing or tech", contact.Type)
+		}
+		if contact.ID == "" {
+			return invalid(field, "empty %s contact ID", contact.Type)
+		}
+	}
+	return nil
+}
+
+// validateHostNames checks that no host name is empty.
+func validateHostNames(field string, hosts []string) error {
+	for _, host := range hosts {
+		if strings.TrimSpace(host) == "" {
+			return invalid(field, "empty host name")
+		}
+	}
+	return nil
+}
+
+// validateStatus checks that each status may be set by a client.
+// https://tools.ietf.org/html/rfc5731#section-2.3
+func validateStatus(field string, status []ObjectStatus) error {
+	for _, s := range status {
+		if !strings.HasPrefix(s.Status, "client") {
+			return invalid(field, "status %q cannot be set by a client", s.Status)
+		}
+	}
+	return nil
+}
+
+// validateAddrs checks that each address is an IP address of the given
+// version ("v4" or "v6").
+func validateAddrs(field, version string, addrs []string) error {
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil || (ip.To4() != nil) != (version == "v4") {
+			return invalid(field, "%q is not an IP%s address", addr, version)
+		}
+	}
+	return nil
+}